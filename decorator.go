@@ -27,8 +27,19 @@ type decoratorFunc[T Module] func(string, Function[T]) Function[T]
 func (d decoratorFunc[T]) Decorate(module string, fn Function[T]) Function[T] { return d(module, fn) }
 
 // Log constructs a function decorator which adds logging to function calls.
+//
+// When the module instance implements types.ErrorTableOwner, error codes
+// returned by the functions are formatted using the table of error strings of
+// the module.
 func Log[T Module](logger *log.Logger) Decorator[T] {
-	return DecoratorFunc(func(module string, fn Function[T]) Function[T] {
+	return LogFormat[T](logger, FormatConfig{})
+}
+
+// LogFormat is like Log but formats the parameters and results of functions
+// with the given configuration, for example to follow nested pointers or render
+// byte buffers as hex dumps.
+func LogFormat[T Module](logger *log.Logger, config FormatConfig) Decorator[T] {
+	return DecoratorFunc(func(moduleName string, fn Function[T]) Function[T] {
 		if logger == nil {
 			return fn
 		}
@@ -42,16 +53,22 @@ func Log[T Module](logger *log.Logger) Decorator[T] {
 				memory := module.Memory()
 				buffer := new(strings.Builder)
 				defer logger.Printf("%s", buffer)
+				output := config.Writer(buffer)
 
-				fmt.Fprintf(buffer, "%s::%s(", module, fn.Name)
-				formatValues(buffer, memory, params, fn.Params)
-				fmt.Fprintf(buffer, ")")
+				var table ErrorTable
+				if owner, ok := any(this).(ErrorTableOwner); ok {
+					table = owner.ErrorTable()
+				}
+
+				fmt.Fprintf(output, "%s::%s(", moduleName, fn.Name)
+				formatValues(output, memory, params, fn.Params, table)
+				fmt.Fprintf(output, ")")
 
 				if panicked {
-					fmt.Fprintf(buffer, " PANIC!")
+					fmt.Fprintf(output, " PANIC!")
 				} else {
-					fmt.Fprintf(buffer, " → ")
-					formatValues(buffer, memory, stack, fn.Results)
+					fmt.Fprintf(output, " → ")
+					formatValues(output, memory, stack, fn.Results, table)
 				}
 			}()
 
@@ -61,13 +78,21 @@ func Log[T Module](logger *log.Logger) Decorator[T] {
 	})
 }
 
-func formatValues(w io.Writer, memory api.Memory, stack []uint64, values []Value) {
+func formatValues(w io.Writer, memory api.Memory, stack []uint64, values []Value, table ErrorTable) {
 	for i, v := range values {
 		if i > 0 {
 			fmt.Fprintf(w, ", ")
 		}
+		formatValue(w, memory, stack, v, table)
+		stack = stack[StackSize(v.ValueTypes()):]
+	}
+}
+
+func formatValue(w io.Writer, memory api.Memory, stack []uint64, v Value, table ErrorTable) {
+	if f, ok := v.(ErrorFormatter); ok && table != nil {
+		f.FormatError(w, memory, stack, table)
+	} else {
 		v.FormatValue(w, memory, stack)
-		stack = stack[len(v.ValueTypes()):]
 	}
 }
 
@@ -105,3 +130,51 @@ func (m *decoratedHostModule[T]) Functions() Functions[T] {
 func (m *decoratedHostModule[T]) Instantiate(ctx context.Context, options ...Option[T]) (T, error) {
 	return m.hostModule.Instantiate(ctx, options...)
 }
+
+// Memory64 constructs a function decorator which switches functions to 64 bits
+// addressing mode (memory64). Parameters and results holding memory addresses
+// or lengths (e.g. Pointer, List, Bytes, String) are declared as i64 values in
+// the function signatures instead of i32.
+//
+// The addressing mode is a property of the guest memory, so the decorator is
+// intended to be applied to all the functions of a host module:
+//
+//	mod := wazergo.Decorate(hostModule, wazergo.Memory64[*Module]())
+//
+// Addresses and lengths which do not fit in 32 bits cannot refer to locations
+// of wazero memories, loading them triggers a panic with a wasm.SEGFAULT64
+// error.
+//
+// The layout of objects in memory is not affected by the addressing mode, so
+// objects holding 32 bits addresses (see types.Layout32), for example pointers
+// stored in struct fields, cannot be exchanged with the guest. The decorator
+// panics if the function has parameters or results loading such objects, or
+// any other values which are not supported in 64 bits addressing mode.
+func Memory64[T Module]() Decorator[T] {
+	return DecoratorFunc(func(module string, fn Function[T]) Function[T] {
+		fn.Params = memory64Values(module, fn.Name, fn.Params)
+		fn.Results = memory64Values(module, fn.Name, fn.Results)
+		return fn
+	})
+}
+
+func memory64Values(module, name string, values []Value) []Value {
+	values64 := make([]Value, len(values))
+	for i, v := range values {
+		if !Supports64(v) {
+			panic(fmt.Errorf("%s.%s: %T is not supported in 64 bits addressing mode", module, name, v))
+		}
+		values64[i] = memory64Value{v}
+	}
+	return values64
+}
+
+type memory64Value struct{ Value }
+
+func (v memory64Value) ValueTypes() []api.ValueType { return ValueTypes64(v.Value) }
+
+func (v memory64Value) HandleTable(this any) api.Closer { return HandleTableOf(this, v.Value) }
+
+func (v memory64Value) FormatError(w io.Writer, memory api.Memory, stack []uint64, table ErrorTable) {
+	formatValue(w, memory, stack, v.Value, table)
+}
@@ -0,0 +1,124 @@
+package wazergo_test
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/stealthrocket/wazergo"
+	"github.com/stealthrocket/wazergo/internal/wasmtest"
+	. "github.com/stealthrocket/wazergo/types"
+	"github.com/stealthrocket/wazergo/wasm"
+	"github.com/tetratelabs/wazero/api"
+)
+
+func TestMemory64(t *testing.T) {
+	fn := F3(func(this *instance, ctx context.Context, b Bytes, p Pointer[Uint32], n Int32) Optional[Uint32] {
+		v := p.Load() + Uint32(len(b)) + Uint32(n)
+		return Res(v)
+	})
+
+	fn64 := Memory64[*instance]().Decorate("test", fn)
+	assertValueTypes(t, fn.Params, []api.ValueType{
+		api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI32,
+	})
+	assertValueTypes(t, fn64.Params, []api.ValueType{
+		api.ValueTypeI64, api.ValueTypeI64, api.ValueTypeI64, api.ValueTypeI32,
+	})
+	assertValueTypes(t, fn64.Results, []api.ValueType{
+		api.ValueTypeI32, api.ValueTypeI32,
+	})
+
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.WriteUint32Le(64, 40)
+
+	module := wasmtest.NewModule("test", wasmtest.Memory(memory))
+	stack := []uint64{0, 2, 64, 0}
+	fn64.Func(new(instance), context.Background(), module, stack)
+	assertEqual(t, stack[:2], []uint64{42, 0})
+
+	defer func() {
+		assertEqual(t, recover(), wasm.SEGFAULT64{Offset: 1 << 32, Length: 4})
+	}()
+	fn64.Func(new(instance), context.Background(), module, []uint64{0, 2, 1 << 32, 0})
+}
+
+func TestMemory64Layout32(t *testing.T) {
+	fn := F2(func(this *instance, ctx context.Context, p Pointer[Pointer[Uint32]], n Int32) Error {
+		return OK
+	})
+
+	defer func() {
+		err, _ := recover().(error)
+		if err == nil || !strings.Contains(err.Error(), "not supported in 64 bits addressing mode") {
+			t.Errorf("wrong panic: %v", err)
+		}
+	}()
+	Memory64[*instance]().Decorate("test", fn)
+}
+
+func assertValueTypes(t *testing.T, values []Value, want []api.ValueType) {
+	t.Helper()
+	var got []api.ValueType
+	for _, v := range values {
+		got = append(got, v.ValueTypes()...)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("value types mismatch: want=%v got=%v", want, got)
+	}
+}
+
+type errorTableInstance struct{ instance }
+
+func (*errorTableInstance) ErrorTable() ErrorTable { return WASIErrors }
+
+func TestLogErrorTable(t *testing.T) {
+	fn := F1(func(this *errorTableInstance, ctx context.Context, fd Int32) Optional[Int32] {
+		if fd < 0 {
+			return Err[Int32](EBADF)
+		}
+		return Res(fd)
+	})
+
+	fn.Name = "fn"
+
+	buffer := new(strings.Builder)
+	logger := log.New(buffer, "", 0)
+	fn = Log[*errorTableInstance](logger).Decorate("test", fn)
+
+	module := wasmtest.NewModule("test")
+	fn.Func(new(errorTableInstance), context.Background(), module, []uint64{api.EncodeI32(-1), 0})
+	fn.Func(new(errorTableInstance), context.Background(), module, []uint64{1, 0})
+
+	assertEqual(t, ""+
+		"test::fn(-1) → ERROR: EBADF (Bad file descriptor)\n"+
+		"test::fn(1) → 1\n",
+		buffer.String(),
+	)
+}
+
+func TestLogFormat(t *testing.T) {
+	fn := F1(func(this *instance, ctx context.Context, b Bytes) Int32 {
+		return Int32(len(b))
+	})
+
+	fn.Name = "fn"
+
+	buffer := new(strings.Builder)
+	logger := log.New(buffer, "", 0)
+	fn = LogFormat[*instance](logger, FormatConfig{HexDump: true}).Decorate("test", fn)
+
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.WriteString(0, "hello")
+	module := wasmtest.NewModule("test", wasmtest.Memory(memory))
+	fn.Func(new(instance), context.Background(), module, []uint64{0, 5})
+
+	assertEqual(t, ""+
+		"test::fn((5 bytes)\n"+
+		"00000000  68 65 6c 6c 6f                                    |hello|\n"+
+		") → 5\n",
+		buffer.String(),
+	)
+}
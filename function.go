@@ -50,7 +50,7 @@ func (f Function[T]) WithFunc(fn func(T, context.Context, api.Module, []uint64))
 
 func countStackValues(values []Value) (count int) {
 	for _, v := range values {
-		count += len(v.ValueTypes())
+		count += StackSize(v.ValueTypes())
 	}
 	return
 }
@@ -58,10 +58,11 @@ func countStackValues(values []Value) (count int) {
 // F0 is the Function constructor for functions accepting no parameters.
 func F0[T any, R Result](fn func(T, context.Context) R) Function[T] {
 	var ret R
+	store := resultStorer[T, R]()
 	return Function[T]{
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			fn(this, ctx).StoreValue(module.Memory(), stack)
+			store(this, ctx, module, module.Memory(), stack, fn(this, ctx))
 		},
 	}
 }
@@ -70,15 +71,16 @@ func F0[T any, R Result](fn func(T, context.Context) R) Function[T] {
 func F1[T any, P Param[P], R Result](fn func(T, context.Context, P) R) Function[T] {
 	var ret R
 	var arg P
-	return Function[T]{
+	load := paramLoader[T, P]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg P
 			var memory = module.Memory()
-			fn(this, ctx, arg.LoadValue(memory, stack)).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx, load(this, ctx, module, memory, stack)))
 		},
-	}
+	})
 }
 
 // F2 is the Function constructor for functions accepting two parameters.
@@ -93,21 +95,22 @@ func F2[
 	var arg2 P2
 	params1 := arg1.ValueTypes()
 	params2 := arg2.ValueTypes()
-	a := len(params1)
-	b := len(params2) + a
-	return Function[T]{
+	a := StackSize(params1)
+	b := StackSize(params2) + a
+	load1 := paramLoader[T, P1]()
+	load2 := paramLoader[T, P2]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg1, arg2},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg1 P1
-			var arg2 P2
 			var memory = module.Memory()
-			fn(this, ctx,
-				arg1.LoadValue(memory, stack[0:a:a]),
-				arg2.LoadValue(memory, stack[a:b:b]),
-			).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx,
+				load1(this, ctx, module, memory, stack[0:a:a]),
+				load2(this, ctx, module, memory, stack[a:b:b]),
+			))
 		},
-	}
+	})
 }
 
 // F3 is the Function constructor for functions accepting three parameters.
@@ -125,24 +128,25 @@ func F3[
 	params1 := arg1.ValueTypes()
 	params2 := arg2.ValueTypes()
 	params3 := arg3.ValueTypes()
-	a := len(params1)
-	b := len(params2) + a
-	c := len(params3) + b
-	return Function[T]{
+	a := StackSize(params1)
+	b := StackSize(params2) + a
+	c := StackSize(params3) + b
+	load1 := paramLoader[T, P1]()
+	load2 := paramLoader[T, P2]()
+	load3 := paramLoader[T, P3]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg1, arg2, arg3},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg1 P1
-			var arg2 P2
-			var arg3 P3
 			var memory = module.Memory()
-			fn(this, ctx,
-				arg1.LoadValue(memory, stack[0:a:a]),
-				arg2.LoadValue(memory, stack[a:b:b]),
-				arg3.LoadValue(memory, stack[b:c:c]),
-			).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx,
+				load1(this, ctx, module, memory, stack[0:a:a]),
+				load2(this, ctx, module, memory, stack[a:b:b]),
+				load3(this, ctx, module, memory, stack[b:c:c]),
+			))
 		},
-	}
+	})
 }
 
 // F4 is the Function constructor for functions accepting four parameters.
@@ -163,27 +167,28 @@ func F4[
 	params2 := arg2.ValueTypes()
 	params3 := arg3.ValueTypes()
 	params4 := arg4.ValueTypes()
-	a := len(params1)
-	b := len(params2) + a
-	c := len(params3) + b
-	d := len(params4) + c
-	return Function[T]{
+	a := StackSize(params1)
+	b := StackSize(params2) + a
+	c := StackSize(params3) + b
+	d := StackSize(params4) + c
+	load1 := paramLoader[T, P1]()
+	load2 := paramLoader[T, P2]()
+	load3 := paramLoader[T, P3]()
+	load4 := paramLoader[T, P4]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg1, arg2, arg3, arg4},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg1 P1
-			var arg2 P2
-			var arg3 P3
-			var arg4 P4
 			var memory = module.Memory()
-			fn(this, ctx,
-				arg1.LoadValue(memory, stack[0:a:a]),
-				arg2.LoadValue(memory, stack[a:b:b]),
-				arg3.LoadValue(memory, stack[b:c:c]),
-				arg4.LoadValue(memory, stack[c:d:d]),
-			).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx,
+				load1(this, ctx, module, memory, stack[0:a:a]),
+				load2(this, ctx, module, memory, stack[a:b:b]),
+				load3(this, ctx, module, memory, stack[b:c:c]),
+				load4(this, ctx, module, memory, stack[c:d:d]),
+			))
 		},
-	}
+	})
 }
 
 // F5 is the Function constructor for functions accepting five parameters.
@@ -207,30 +212,31 @@ func F5[
 	params3 := arg3.ValueTypes()
 	params4 := arg4.ValueTypes()
 	params5 := arg5.ValueTypes()
-	a := len(params1)
-	b := len(params2) + a
-	c := len(params3) + b
-	d := len(params4) + c
-	e := len(params5) + d
-	return Function[T]{
+	a := StackSize(params1)
+	b := StackSize(params2) + a
+	c := StackSize(params3) + b
+	d := StackSize(params4) + c
+	e := StackSize(params5) + d
+	load1 := paramLoader[T, P1]()
+	load2 := paramLoader[T, P2]()
+	load3 := paramLoader[T, P3]()
+	load4 := paramLoader[T, P4]()
+	load5 := paramLoader[T, P5]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg1, arg2, arg3, arg4, arg5},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg1 P1
-			var arg2 P2
-			var arg3 P3
-			var arg4 P4
-			var arg5 P5
 			var memory = module.Memory()
-			fn(this, ctx,
-				arg1.LoadValue(memory, stack[0:a:a]),
-				arg2.LoadValue(memory, stack[a:b:b]),
-				arg3.LoadValue(memory, stack[b:c:c]),
-				arg4.LoadValue(memory, stack[c:d:d]),
-				arg5.LoadValue(memory, stack[d:e:e]),
-			).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx,
+				load1(this, ctx, module, memory, stack[0:a:a]),
+				load2(this, ctx, module, memory, stack[a:b:b]),
+				load3(this, ctx, module, memory, stack[b:c:c]),
+				load4(this, ctx, module, memory, stack[c:d:d]),
+				load5(this, ctx, module, memory, stack[d:e:e]),
+			))
 		},
-	}
+	})
 }
 
 // F6 is the Function constructor for functions accepting six parameters.
@@ -257,33 +263,34 @@ func F6[
 	params4 := arg4.ValueTypes()
 	params5 := arg5.ValueTypes()
 	params6 := arg6.ValueTypes()
-	a := len(params1)
-	b := len(params2) + a
-	c := len(params3) + b
-	d := len(params4) + c
-	e := len(params5) + d
-	f := len(params6) + e
-	return Function[T]{
+	a := StackSize(params1)
+	b := StackSize(params2) + a
+	c := StackSize(params3) + b
+	d := StackSize(params4) + c
+	e := StackSize(params5) + d
+	f := StackSize(params6) + e
+	load1 := paramLoader[T, P1]()
+	load2 := paramLoader[T, P2]()
+	load3 := paramLoader[T, P3]()
+	load4 := paramLoader[T, P4]()
+	load5 := paramLoader[T, P5]()
+	load6 := paramLoader[T, P6]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg1, arg2, arg3, arg4, arg5, arg6},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg1 P1
-			var arg2 P2
-			var arg3 P3
-			var arg4 P4
-			var arg5 P5
-			var arg6 P6
 			var memory = module.Memory()
-			fn(this, ctx,
-				arg1.LoadValue(memory, stack[0:a:a]),
-				arg2.LoadValue(memory, stack[a:b:b]),
-				arg3.LoadValue(memory, stack[b:c:c]),
-				arg4.LoadValue(memory, stack[c:d:d]),
-				arg5.LoadValue(memory, stack[d:e:e]),
-				arg6.LoadValue(memory, stack[e:f:f]),
-			).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx,
+				load1(this, ctx, module, memory, stack[0:a:a]),
+				load2(this, ctx, module, memory, stack[a:b:b]),
+				load3(this, ctx, module, memory, stack[b:c:c]),
+				load4(this, ctx, module, memory, stack[c:d:d]),
+				load5(this, ctx, module, memory, stack[d:e:e]),
+				load6(this, ctx, module, memory, stack[e:f:f]),
+			))
 		},
-	}
+	})
 }
 
 // F7 is the Function constructor for functions accepting seven parameters.
@@ -313,36 +320,37 @@ func F7[
 	params5 := arg5.ValueTypes()
 	params6 := arg6.ValueTypes()
 	params7 := arg7.ValueTypes()
-	a := len(params1)
-	b := len(params2) + a
-	c := len(params3) + b
-	d := len(params4) + c
-	e := len(params5) + d
-	f := len(params6) + e
-	g := len(params7) + f
-	return Function[T]{
+	a := StackSize(params1)
+	b := StackSize(params2) + a
+	c := StackSize(params3) + b
+	d := StackSize(params4) + c
+	e := StackSize(params5) + d
+	f := StackSize(params6) + e
+	g := StackSize(params7) + f
+	load1 := paramLoader[T, P1]()
+	load2 := paramLoader[T, P2]()
+	load3 := paramLoader[T, P3]()
+	load4 := paramLoader[T, P4]()
+	load5 := paramLoader[T, P5]()
+	load6 := paramLoader[T, P6]()
+	load7 := paramLoader[T, P7]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg1, arg2, arg3, arg4, arg5, arg6, arg7},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg1 P1
-			var arg2 P2
-			var arg3 P3
-			var arg4 P4
-			var arg5 P5
-			var arg6 P6
-			var arg7 P7
 			var memory = module.Memory()
-			fn(this, ctx,
-				arg1.LoadValue(memory, stack[0:a:a]),
-				arg2.LoadValue(memory, stack[a:b:b]),
-				arg3.LoadValue(memory, stack[b:c:c]),
-				arg4.LoadValue(memory, stack[c:d:d]),
-				arg5.LoadValue(memory, stack[d:e:e]),
-				arg6.LoadValue(memory, stack[e:f:f]),
-				arg7.LoadValue(memory, stack[f:g:g]),
-			).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx,
+				load1(this, ctx, module, memory, stack[0:a:a]),
+				load2(this, ctx, module, memory, stack[a:b:b]),
+				load3(this, ctx, module, memory, stack[b:c:c]),
+				load4(this, ctx, module, memory, stack[c:d:d]),
+				load5(this, ctx, module, memory, stack[d:e:e]),
+				load6(this, ctx, module, memory, stack[e:f:f]),
+				load7(this, ctx, module, memory, stack[f:g:g]),
+			))
 		},
-	}
+	})
 }
 
 // F8 is the Function constructor for functions accepting eight parameters.
@@ -375,39 +383,40 @@ func F8[
 	params6 := arg6.ValueTypes()
 	params7 := arg7.ValueTypes()
 	params8 := arg8.ValueTypes()
-	a := len(params1)
-	b := len(params2) + a
-	c := len(params3) + b
-	d := len(params4) + c
-	e := len(params5) + d
-	f := len(params6) + e
-	g := len(params7) + f
-	h := len(params8) + g
-	return Function[T]{
+	a := StackSize(params1)
+	b := StackSize(params2) + a
+	c := StackSize(params3) + b
+	d := StackSize(params4) + c
+	e := StackSize(params5) + d
+	f := StackSize(params6) + e
+	g := StackSize(params7) + f
+	h := StackSize(params8) + g
+	load1 := paramLoader[T, P1]()
+	load2 := paramLoader[T, P2]()
+	load3 := paramLoader[T, P3]()
+	load4 := paramLoader[T, P4]()
+	load5 := paramLoader[T, P5]()
+	load6 := paramLoader[T, P6]()
+	load7 := paramLoader[T, P7]()
+	load8 := paramLoader[T, P8]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg1 P1
-			var arg2 P2
-			var arg3 P3
-			var arg4 P4
-			var arg5 P5
-			var arg6 P6
-			var arg7 P7
-			var arg8 P8
 			var memory = module.Memory()
-			fn(this, ctx,
-				arg1.LoadValue(memory, stack[0:a:a]),
-				arg2.LoadValue(memory, stack[a:b:b]),
-				arg3.LoadValue(memory, stack[b:c:c]),
-				arg4.LoadValue(memory, stack[c:d:d]),
-				arg5.LoadValue(memory, stack[d:e:e]),
-				arg6.LoadValue(memory, stack[e:f:f]),
-				arg7.LoadValue(memory, stack[f:g:g]),
-				arg8.LoadValue(memory, stack[g:h:h]),
-			).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx,
+				load1(this, ctx, module, memory, stack[0:a:a]),
+				load2(this, ctx, module, memory, stack[a:b:b]),
+				load3(this, ctx, module, memory, stack[b:c:c]),
+				load4(this, ctx, module, memory, stack[c:d:d]),
+				load5(this, ctx, module, memory, stack[d:e:e]),
+				load6(this, ctx, module, memory, stack[e:f:f]),
+				load7(this, ctx, module, memory, stack[f:g:g]),
+				load8(this, ctx, module, memory, stack[g:h:h]),
+			))
 		},
-	}
+	})
 }
 
 // F9 is the Function constructor for functions accepting nine parameters.
@@ -443,42 +452,43 @@ func F9[
 	params7 := arg7.ValueTypes()
 	params8 := arg8.ValueTypes()
 	params9 := arg9.ValueTypes()
-	a := len(params1)
-	b := len(params2) + a
-	c := len(params3) + b
-	d := len(params4) + c
-	e := len(params5) + d
-	f := len(params6) + e
-	g := len(params7) + f
-	h := len(params8) + g
-	i := len(params9) + h
-	return Function[T]{
+	a := StackSize(params1)
+	b := StackSize(params2) + a
+	c := StackSize(params3) + b
+	d := StackSize(params4) + c
+	e := StackSize(params5) + d
+	f := StackSize(params6) + e
+	g := StackSize(params7) + f
+	h := StackSize(params8) + g
+	i := StackSize(params9) + h
+	load1 := paramLoader[T, P1]()
+	load2 := paramLoader[T, P2]()
+	load3 := paramLoader[T, P3]()
+	load4 := paramLoader[T, P4]()
+	load5 := paramLoader[T, P5]()
+	load6 := paramLoader[T, P6]()
+	load7 := paramLoader[T, P7]()
+	load8 := paramLoader[T, P8]()
+	load9 := paramLoader[T, P9]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg1 P1
-			var arg2 P2
-			var arg3 P3
-			var arg4 P4
-			var arg5 P5
-			var arg6 P6
-			var arg7 P7
-			var arg8 P8
-			var arg9 P9
 			var memory = module.Memory()
-			fn(this, ctx,
-				arg1.LoadValue(memory, stack[0:a:a]),
-				arg2.LoadValue(memory, stack[a:b:b]),
-				arg3.LoadValue(memory, stack[b:c:c]),
-				arg4.LoadValue(memory, stack[c:d:d]),
-				arg5.LoadValue(memory, stack[d:e:e]),
-				arg6.LoadValue(memory, stack[e:f:f]),
-				arg7.LoadValue(memory, stack[f:g:g]),
-				arg8.LoadValue(memory, stack[g:h:h]),
-				arg9.LoadValue(memory, stack[h:i:i]),
-			).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx,
+				load1(this, ctx, module, memory, stack[0:a:a]),
+				load2(this, ctx, module, memory, stack[a:b:b]),
+				load3(this, ctx, module, memory, stack[b:c:c]),
+				load4(this, ctx, module, memory, stack[c:d:d]),
+				load5(this, ctx, module, memory, stack[d:e:e]),
+				load6(this, ctx, module, memory, stack[e:f:f]),
+				load7(this, ctx, module, memory, stack[f:g:g]),
+				load8(this, ctx, module, memory, stack[g:h:h]),
+				load9(this, ctx, module, memory, stack[h:i:i]),
+			))
 		},
-	}
+	})
 }
 
 // F10 is the Function constructor for functions accepting ten parameters.
@@ -517,45 +527,46 @@ func F10[
 	params8 := arg8.ValueTypes()
 	params9 := arg9.ValueTypes()
 	params10 := arg10.ValueTypes()
-	a := len(params1)
-	b := len(params2) + a
-	c := len(params3) + b
-	d := len(params4) + c
-	e := len(params5) + d
-	f := len(params6) + e
-	g := len(params7) + f
-	h := len(params8) + g
-	i := len(params9) + h
-	j := len(params10) + i
-	return Function[T]{
+	a := StackSize(params1)
+	b := StackSize(params2) + a
+	c := StackSize(params3) + b
+	d := StackSize(params4) + c
+	e := StackSize(params5) + d
+	f := StackSize(params6) + e
+	g := StackSize(params7) + f
+	h := StackSize(params8) + g
+	i := StackSize(params9) + h
+	j := StackSize(params10) + i
+	load1 := paramLoader[T, P1]()
+	load2 := paramLoader[T, P2]()
+	load3 := paramLoader[T, P3]()
+	load4 := paramLoader[T, P4]()
+	load5 := paramLoader[T, P5]()
+	load6 := paramLoader[T, P6]()
+	load7 := paramLoader[T, P7]()
+	load8 := paramLoader[T, P8]()
+	load9 := paramLoader[T, P9]()
+	load10 := paramLoader[T, P10]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg1 P1
-			var arg2 P2
-			var arg3 P3
-			var arg4 P4
-			var arg5 P5
-			var arg6 P6
-			var arg7 P7
-			var arg8 P8
-			var arg9 P9
-			var arg10 P10
 			var memory = module.Memory()
-			fn(this, ctx,
-				arg1.LoadValue(memory, stack[0:a:a]),
-				arg2.LoadValue(memory, stack[a:b:b]),
-				arg3.LoadValue(memory, stack[b:c:c]),
-				arg4.LoadValue(memory, stack[c:d:d]),
-				arg5.LoadValue(memory, stack[d:e:e]),
-				arg6.LoadValue(memory, stack[e:f:f]),
-				arg7.LoadValue(memory, stack[f:g:g]),
-				arg8.LoadValue(memory, stack[g:h:h]),
-				arg9.LoadValue(memory, stack[h:i:i]),
-				arg10.LoadValue(memory, stack[i:j:j]),
-			).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx,
+				load1(this, ctx, module, memory, stack[0:a:a]),
+				load2(this, ctx, module, memory, stack[a:b:b]),
+				load3(this, ctx, module, memory, stack[b:c:c]),
+				load4(this, ctx, module, memory, stack[c:d:d]),
+				load5(this, ctx, module, memory, stack[d:e:e]),
+				load6(this, ctx, module, memory, stack[e:f:f]),
+				load7(this, ctx, module, memory, stack[f:g:g]),
+				load8(this, ctx, module, memory, stack[g:h:h]),
+				load9(this, ctx, module, memory, stack[h:i:i]),
+				load10(this, ctx, module, memory, stack[i:j:j]),
+			))
 		},
-	}
+	})
 }
 
 // F11 is the Function constructor for functions accepting eleven parameters.
@@ -597,48 +608,49 @@ func F11[
 	params9 := arg9.ValueTypes()
 	params10 := arg10.ValueTypes()
 	params11 := arg11.ValueTypes()
-	a := len(params1)
-	b := len(params2) + a
-	c := len(params3) + b
-	d := len(params4) + c
-	e := len(params5) + d
-	f := len(params6) + e
-	g := len(params7) + f
-	h := len(params8) + g
-	i := len(params9) + h
-	j := len(params10) + i
-	k := len(params11) + j
-	return Function[T]{
+	a := StackSize(params1)
+	b := StackSize(params2) + a
+	c := StackSize(params3) + b
+	d := StackSize(params4) + c
+	e := StackSize(params5) + d
+	f := StackSize(params6) + e
+	g := StackSize(params7) + f
+	h := StackSize(params8) + g
+	i := StackSize(params9) + h
+	j := StackSize(params10) + i
+	k := StackSize(params11) + j
+	load1 := paramLoader[T, P1]()
+	load2 := paramLoader[T, P2]()
+	load3 := paramLoader[T, P3]()
+	load4 := paramLoader[T, P4]()
+	load5 := paramLoader[T, P5]()
+	load6 := paramLoader[T, P6]()
+	load7 := paramLoader[T, P7]()
+	load8 := paramLoader[T, P8]()
+	load9 := paramLoader[T, P9]()
+	load10 := paramLoader[T, P10]()
+	load11 := paramLoader[T, P11]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg1 P1
-			var arg2 P2
-			var arg3 P3
-			var arg4 P4
-			var arg5 P5
-			var arg6 P6
-			var arg7 P7
-			var arg8 P8
-			var arg9 P9
-			var arg10 P10
-			var arg11 P11
 			var memory = module.Memory()
-			fn(this, ctx,
-				arg1.LoadValue(memory, stack[0:a:a]),
-				arg2.LoadValue(memory, stack[a:b:b]),
-				arg3.LoadValue(memory, stack[b:c:c]),
-				arg4.LoadValue(memory, stack[c:d:d]),
-				arg5.LoadValue(memory, stack[d:e:e]),
-				arg6.LoadValue(memory, stack[e:f:f]),
-				arg7.LoadValue(memory, stack[f:g:g]),
-				arg8.LoadValue(memory, stack[g:h:h]),
-				arg9.LoadValue(memory, stack[h:i:i]),
-				arg10.LoadValue(memory, stack[i:j:j]),
-				arg11.LoadValue(memory, stack[j:k:k]),
-			).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx,
+				load1(this, ctx, module, memory, stack[0:a:a]),
+				load2(this, ctx, module, memory, stack[a:b:b]),
+				load3(this, ctx, module, memory, stack[b:c:c]),
+				load4(this, ctx, module, memory, stack[c:d:d]),
+				load5(this, ctx, module, memory, stack[d:e:e]),
+				load6(this, ctx, module, memory, stack[e:f:f]),
+				load7(this, ctx, module, memory, stack[f:g:g]),
+				load8(this, ctx, module, memory, stack[g:h:h]),
+				load9(this, ctx, module, memory, stack[h:i:i]),
+				load10(this, ctx, module, memory, stack[i:j:j]),
+				load11(this, ctx, module, memory, stack[j:k:k]),
+			))
 		},
-	}
+	})
 }
 
 // F12 is the Function constructor for functions accepting twelve parameters.
@@ -683,49 +695,140 @@ func F12[
 	params10 := arg10.ValueTypes()
 	params11 := arg11.ValueTypes()
 	params12 := arg12.ValueTypes()
-	a := len(params1)
-	b := len(params2) + a
-	c := len(params3) + b
-	d := len(params4) + c
-	e := len(params5) + d
-	f := len(params6) + e
-	g := len(params7) + f
-	h := len(params8) + g
-	i := len(params9) + h
-	j := len(params10) + i
-	k := len(params11) + j
-	l := len(params12) + k
-	return Function[T]{
+	a := StackSize(params1)
+	b := StackSize(params2) + a
+	c := StackSize(params3) + b
+	d := StackSize(params4) + c
+	e := StackSize(params5) + d
+	f := StackSize(params6) + e
+	g := StackSize(params7) + f
+	h := StackSize(params8) + g
+	i := StackSize(params9) + h
+	j := StackSize(params10) + i
+	k := StackSize(params11) + j
+	l := StackSize(params12) + k
+	load1 := paramLoader[T, P1]()
+	load2 := paramLoader[T, P2]()
+	load3 := paramLoader[T, P3]()
+	load4 := paramLoader[T, P4]()
+	load5 := paramLoader[T, P5]()
+	load6 := paramLoader[T, P6]()
+	load7 := paramLoader[T, P7]()
+	load8 := paramLoader[T, P8]()
+	load9 := paramLoader[T, P9]()
+	load10 := paramLoader[T, P10]()
+	load11 := paramLoader[T, P11]()
+	load12 := paramLoader[T, P12]()
+	store := resultStorer[T, R]()
+	return validated[T, R](Function[T]{
 		Params:  []Value{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12},
 		Results: []Value{ret},
 		Func: func(this T, ctx context.Context, module api.Module, stack []uint64) {
-			var arg1 P1
-			var arg2 P2
-			var arg3 P3
-			var arg4 P4
-			var arg5 P5
-			var arg6 P6
-			var arg7 P7
-			var arg8 P8
-			var arg9 P9
-			var arg10 P10
-			var arg11 P11
-			var arg12 P12
 			var memory = module.Memory()
-			fn(this, ctx,
-				arg1.LoadValue(memory, stack[0:a:a]),
-				arg2.LoadValue(memory, stack[a:b:b]),
-				arg3.LoadValue(memory, stack[b:c:c]),
-				arg4.LoadValue(memory, stack[c:d:d]),
-				arg5.LoadValue(memory, stack[d:e:e]),
-				arg6.LoadValue(memory, stack[e:f:f]),
-				arg7.LoadValue(memory, stack[f:g:g]),
-				arg8.LoadValue(memory, stack[g:h:h]),
-				arg9.LoadValue(memory, stack[h:i:i]),
-				arg10.LoadValue(memory, stack[i:j:j]),
-				arg11.LoadValue(memory, stack[j:k:k]),
-				arg12.LoadValue(memory, stack[k:l:l]),
-			).StoreValue(memory, stack)
+			store(this, ctx, module, memory, stack, fn(this, ctx,
+				load1(this, ctx, module, memory, stack[0:a:a]),
+				load2(this, ctx, module, memory, stack[a:b:b]),
+				load3(this, ctx, module, memory, stack[b:c:c]),
+				load4(this, ctx, module, memory, stack[c:d:d]),
+				load5(this, ctx, module, memory, stack[d:e:e]),
+				load6(this, ctx, module, memory, stack[e:f:f]),
+				load7(this, ctx, module, memory, stack[f:g:g]),
+				load8(this, ctx, module, memory, stack[g:h:h]),
+				load9(this, ctx, module, memory, stack[h:i:i]),
+				load10(this, ctx, module, memory, stack[i:j:j]),
+				load11(this, ctx, module, memory, stack[j:k:k]),
+				load12(this, ctx, module, memory, stack[k:l:l]),
+			))
 		},
+	})
+}
+
+// paramLoader returns a function loading parameters of type P, which calls the
+// LoadContextValue method if P implements ContextParam[P], or LoadValue if it
+// does not. The choice is made once when constructing the function so there is
+// no need to check for the interface on each call.
+//
+// If P implements Validator, the parameters are validated before being loaded,
+// and the function panics with an invalidParam value when they are rejected,
+// which the host function returned by validated recovers from.
+func paramLoader[T any, P Param[P]]() func(T, context.Context, api.Module, api.Memory, []uint64) P {
+	var arg P
+	load := func(_ T, _ context.Context, _ api.Module, memory api.Memory, stack []uint64) P {
+		return arg.LoadValue(memory, stack)
+	}
+	if param, ok := any(arg).(ContextParam[P]); ok {
+		load = func(this T, ctx context.Context, module api.Module, _ api.Memory, stack []uint64) P {
+			return param.LoadContextValue(ctx, this, module, stack)
+		}
+	}
+	if validator, ok := any(arg).(Validator); ok {
+		loadValue := load
+		load = func(this T, ctx context.Context, module api.Module, memory api.Memory, stack []uint64) P {
+			if err := validator.ValidateValue(memory, stack); err != nil {
+				panic(invalidParam{err})
+			}
+			return loadValue(this, ctx, module, memory, stack)
+		}
+	}
+	return load
+}
+
+// invalidParam is the value that parameter loaders panic with when parameters
+// are rejected by their ValidateValue method.
+type invalidParam struct{ err error }
+
+// validated returns f with its Func field wrapped to recover from the panics of
+// parameter loaders when parameters are rejected, in which case the error is
+// returned to the guest if R implements ErrorResult, or the call panics with
+// the error otherwise. f is returned unchanged if none of its parameters
+// implement Validator, so other functions do not pay the cost of recovering
+// from panics.
+func validated[T any, R Result](f Function[T]) Function[T] {
+	if !hasValidator(f.Params) {
+		return f
+	}
+	var ret R
+	store := resultStorer[T, R]()
+	call := f.Func
+	f.Func = func(this T, ctx context.Context, module api.Module, stack []uint64) {
+		defer func() {
+			if e := recover(); e != nil {
+				p, ok := e.(invalidParam)
+				if !ok {
+					panic(e)
+				}
+				r, ok := any(ret).(ErrorResult)
+				if !ok {
+					panic(p.err)
+				}
+				store(this, ctx, module, module.Memory(), stack, r.WithError(p.err).(R))
+			}
+		}()
+		call(this, ctx, module, stack)
+	}
+	return f
+}
+
+func hasValidator(params []Value) bool {
+	for _, p := range params {
+		if _, ok := p.(Validator); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resultStorer returns a function storing results of type R, which calls the
+// StoreContextValue method if R implements ContextResult, or StoreValue if it
+// does not.
+func resultStorer[T any, R Result]() func(T, context.Context, api.Module, api.Memory, []uint64, R) {
+	var ret R
+	if _, ok := any(ret).(ContextResult); ok {
+		return func(this T, ctx context.Context, module api.Module, _ api.Memory, stack []uint64, ret R) {
+			any(ret).(ContextResult).StoreContextValue(ctx, this, module, stack)
+		}
+	}
+	return func(_ T, _ context.Context, _ api.Module, memory api.Memory, stack []uint64, ret R) {
+		ret.StoreValue(memory, stack)
 	}
 }
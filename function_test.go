@@ -3,8 +3,11 @@ package wazergo_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io/fs"
 	"reflect"
 	"strconv"
+	"syscall"
 	"testing"
 
 	. "github.com/stealthrocket/wazergo"
@@ -60,6 +63,326 @@ func TestFunc2(t *testing.T) {
 	)
 }
 
+type handleInstance struct {
+	files HandleTable[string]
+}
+
+func (*handleInstance) Close(context.Context) error { return nil }
+
+func (m *handleInstance) Handles() *HandleTable[string] { return &m.files }
+
+func TestFuncHandle(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	module := wasmtest.NewModule("test", wasmtest.Memory(memory))
+	ctx := context.Background()
+
+	this := new(handleInstance)
+	h := this.files.Insert("hello")
+
+	fn := F1(func(this *handleInstance, ctx context.Context, h Handle[string]) Optional[Uint32] {
+		v, err := h.Value()
+		return Opt(Uint32(len(v)), err)
+	})
+
+	assertEqual(t, Res(Uint32(5)), wasmtest.Call[Optional[Uint32]](fn, ctx, module, this, h))
+	assertEqual(t, Err[Uint32](EBADF), wasmtest.Call[Optional[Uint32]](fn, ctx, module, this, Int32(1)))
+}
+
+func TestFuncOptionalHandle(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	module := wasmtest.NewModule("test", wasmtest.Memory(memory))
+	ctx := context.Background()
+
+	this := new(handleInstance)
+	this.files.Insert("")
+	h := this.files.Insert("hello")
+	this.files.Remove(0)
+
+	fn := F1(func(this *handleInstance, ctx context.Context, opt Optional[Handle[string]]) Optional[Uint32] {
+		if err := opt.Error(); err != nil {
+			return Err[Uint32](err)
+		}
+		v, err := opt.Result().Value()
+		return Opt(Uint32(len(v)), err)
+	})
+
+	assertEqual(t, Res(Uint32(5)), wasmtest.Call[Optional[Uint32]](fn, ctx, module, this, Res(h)))
+	assertEqual(t, Err[Uint32](EBADF), wasmtest.Call[Optional[Uint32]](fn, ctx, module, this, Res(Handle[string]{})))
+	assertEqual(t, Err[Uint32](ENOENT), wasmtest.Call[Optional[Uint32]](fn, ctx, module, this, Err[Handle[string]](ENOENT)))
+}
+
+func TestFuncValidate(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.Write(0, []byte("hello\xff"))
+	memory.Write(16, []byte{'h', 0, 0x34, 0xD8})
+	module := wasmtest.NewModule("test", wasmtest.Memory(memory))
+	ctx := context.Background()
+
+	calls := 0
+	fn := F2(func(this *instance, ctx context.Context, s UTF8, u UTF16) Optional[Int32] {
+		calls++
+		return Res(Int32(len(s) + len(u)))
+	})
+
+	stack := []uint64{0, 5, 16, 1}
+	fn.Func(nil, ctx, module, stack)
+	assertEqual(t, []uint64{6, 0}, stack[:2])
+
+	stack = []uint64{0, 6, 16, 1}
+	fn.Func(nil, ctx, module, stack)
+	assertEqual(t, []uint64{0, api.EncodeI32(int32(EILSEQ))}, stack[:2])
+
+	stack = []uint64{0, 5, 16, 2}
+	fn.Func(nil, ctx, module, stack)
+	assertEqual(t, []uint64{0, api.EncodeI32(int32(EILSEQ))}, stack[:2])
+	assertEqual(t, 1, calls)
+
+	// Results which cannot represent errors panic instead.
+	fn = F1(func(this *instance, ctx context.Context, s UTF8) Int32 {
+		return Int32(len(s))
+	})
+	defer func() {
+		assertEqual(t, any(EILSEQ), recover())
+	}()
+	fn.Func(nil, ctx, module, []uint64{0, 6})
+	t.Error("invalid parameter did not panic")
+}
+
+func TestFuncAllocErrorMessage(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	i32 := []api.ValueType{api.ValueTypeI32}
+	malloc := wasmtest.NewFunction(i32, i32, func(ctx context.Context, params ...uint64) ([]uint64, error) {
+		return []uint64{1024}, nil
+	})
+	module := wasmtest.NewModule("test", wasmtest.Memory(memory), wasmtest.Export("malloc", malloc))
+	ctx := context.Background()
+
+	fn := F1(func(this *instance, ctx context.Context, key String) AllocErrorMessage[Malloc] {
+		return AllocErrMsg[Malloc](fmt.Errorf("key %s is read-only: %w", key, EPERM))
+	})
+
+	const text = "key foo is read-only: errno(63)"
+	memory.Write(16, []byte("foo"))
+	stack := []uint64{16, 3, 0}
+
+	fn.Func(new(instance), ctx, module, stack)
+	assertEqual(t, []uint64{uint64(EPERM), 1024, uint64(len(text))}, stack)
+
+	msg, _ := memory.Read(1024, uint32(len(text)))
+	assertEqual(t, text, string(msg))
+}
+
+type errnoInstance struct {
+	instance
+	errors ErrnoRegistry
+}
+
+func (m *errnoInstance) ErrnoRegistry() *ErrnoRegistry { return &m.errors }
+
+func TestFuncErrnoRegistry(t *testing.T) {
+	module := wasmtest.NewModule("test")
+	ctx := context.Background()
+
+	errReadOnly := errors.New("read-only")
+	this := new(errnoInstance)
+	this.errors.Register(errReadOnly, EROFS)
+	this.errors.Register(fs.ErrNotExist, EBADF)
+
+	fn := F1(func(this *errnoInstance, ctx context.Context, v Int32) Error {
+		switch v {
+		case 0:
+			return Fail(fmt.Errorf("put: %w", errReadOnly))
+		case 1:
+			return Fail(&fs.PathError{Op: "open", Path: "/", Err: syscall.ENOENT})
+		default:
+			return Fail(EPIPE)
+		}
+	})
+
+	assertEqual(t, Fail(EROFS), wasmtest.Call[Error](fn, ctx, module, this, Int32(0)))
+	assertEqual(t, Fail(EBADF), wasmtest.Call[Error](fn, ctx, module, this, Int32(1)))
+	assertEqual(t, Fail(EPIPE), wasmtest.Call[Error](fn, ctx, module, this, Int32(2)))
+
+	// Errors which are not found in the registry of the module instance use
+	// the global mappings.
+	assertEqual(t, Fail(ENOENT), wasmtest.Call[Error](fn, ctx, module, new(errnoInstance), Int32(1)))
+}
+
+func TestFuncAllocErrorMessage64(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	i64 := []api.ValueType{api.ValueTypeI64}
+	malloc := wasmtest.NewFunction(i64, i64, func(ctx context.Context, params ...uint64) ([]uint64, error) {
+		return []uint64{2048}, nil
+	})
+	module := wasmtest.NewModule("test", wasmtest.Memory(memory), wasmtest.Export("malloc", malloc))
+	ctx := context.Background()
+
+	fn := Memory64[*instance]().Decorate("test", F0(func(this *instance, ctx context.Context) AllocErrorMessage[Malloc] {
+		return AllocErrMsg[Malloc](EPERM)
+	}))
+	assertEqual(t, []api.ValueType{api.ValueTypeI32, api.ValueTypeI64, api.ValueTypeI64}, fn.Results[0].ValueTypes())
+
+	const text = "errno(63)"
+	stack := []uint64{0, 0, 0}
+
+	fn.Func(new(instance), ctx, module, stack)
+	assertEqual(t, []uint64{uint64(EPERM), 2048, uint64(len(text))}, stack)
+
+	msg, _ := memory.Read(2048, uint32(len(text)))
+	assertEqual(t, text, string(msg))
+}
+
+type refInstance struct {
+	refs HandleTable[string]
+}
+
+func (*refInstance) Close(context.Context) error { return nil }
+
+func (m *refInstance) ExternRefs() *HandleTable[string] { return &m.refs }
+
+func TestFuncExternRef(t *testing.T) {
+	module := wasmtest.NewModule("test")
+	ctx := context.Background()
+	this := new(refInstance)
+
+	newRef := F1(func(this *refInstance, ctx context.Context, v Int32) ExternRef[string] {
+		if v < 0 {
+			return ExternRef[string]{}
+		}
+		return Ref(strconv.Itoa(int(v)))
+	})
+	getRef := F1(func(this *refInstance, ctx context.Context, ref ExternRef[string]) Optional[Int32] {
+		s, err := ref.Value()
+		if err != nil {
+			return Err[Int32](err)
+		}
+		i, err := strconv.Atoi(s)
+		return Opt(Int32(i), err)
+	})
+
+	stack := []uint64{42}
+	newRef.Func(this, ctx, module, stack)
+	assertEqual(t, uint64(1), stack[0])
+	assertEqual(t, 1, this.refs.Len())
+
+	stack = []uint64{api.EncodeI32(-1)}
+	newRef.Func(this, ctx, module, stack)
+	assertEqual(t, uint64(0), stack[0])
+	assertEqual(t, 1, this.refs.Len())
+
+	assertEqual(t, Res(Int32(42)), wasmtest.Call[Optional[Int32]](getRef, ctx, module, this, Uint64(1)))
+	assertEqual(t, Err[Int32](EBADF), wasmtest.Call[Optional[Int32]](getRef, ctx, module, this, Uint64(2)))
+	assertEqual(t, Err[Int32](EINVAL), wasmtest.Call[Optional[Int32]](getRef, ctx, module, this, Uint64(0)))
+}
+
+func TestFuncExportRef(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	i32 := []api.ValueType{api.ValueTypeI32}
+	double := wasmtest.NewFunction(i32, i32, func(ctx context.Context, params ...uint64) ([]uint64, error) {
+		return []uint64{2 * params[0]}, nil
+	})
+	module := wasmtest.NewModule("test", wasmtest.Memory(memory), wasmtest.Export("double", double))
+	ctx := context.Background()
+
+	fn := F2(func(this *instance, ctx context.Context, fn ExportRef, v Int32) Optional[Int32] {
+		ret, err := fn.Call(ctx, api.EncodeI32(int32(v)))
+		if err != nil {
+			return Err[Int32](err)
+		}
+		return Res(Int32(ret[0]))
+	})
+
+	assertEqual(t, Res(Int32(42)), wasmtest.Call[Optional[Int32]](fn, ctx, module, new(instance), wasmtest.Bytes("double"), Int32(21)))
+	assertEqual(t, Err[Int32](ENOENT), wasmtest.Call[Optional[Int32]](fn, ctx, module, new(instance), wasmtest.Bytes("triple"), Int32(21)))
+	assertEqual(t, Err[Int32](EINVAL), wasmtest.Call[Optional[Int32]](fn, ctx, module, new(instance), wasmtest.Bytes(""), Int32(21)))
+}
+
+func TestFuncCallback(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	ctx := context.Background()
+
+	var sum Function[*instance]
+	var module *wasmtest.Module
+
+	sum = F2(func(this *instance, ctx context.Context, fn Callback1[Int32, Int32], n Int32) Optional[Int32] {
+		var sum Int32
+		for i := Int32(0); i < n; i++ {
+			v, err := fn.Call(ctx, i)
+			if err != nil {
+				return Err[Int32](err)
+			}
+			sum += v
+		}
+		return Res(sum)
+	})
+
+	i32 := []api.ValueType{api.ValueTypeI32}
+	square := wasmtest.NewFunction(i32, i32, func(ctx context.Context, params ...uint64) ([]uint64, error) {
+		return []uint64{params[0] * params[0]}, nil
+	})
+	reenter := wasmtest.NewFunction(i32, i32, func(ctx context.Context, params ...uint64) ([]uint64, error) {
+		stack := []uint64{0, 6, params[0]}
+		memory.Write(0, []byte("square"))
+		sum.Func(new(instance), ctx, module, stack)
+		if errno := Errno(stack[1]); errno != 0 {
+			return nil, errno
+		}
+		return stack[:1], nil
+	})
+	recurse := wasmtest.NewFunction(i32, i32, func(ctx context.Context, params ...uint64) ([]uint64, error) {
+		stack := []uint64{0, 7, 1}
+		memory.Write(0, []byte("recurse"))
+		sum.Func(new(instance), ctx, module, stack)
+		if errno := Errno(stack[1]); errno != 0 {
+			return nil, errno
+		}
+		return stack[:1], nil
+	})
+	module = wasmtest.NewModule("test",
+		wasmtest.Memory(memory),
+		wasmtest.Export("square", square),
+		wasmtest.Export("reenter", reenter),
+		wasmtest.Export("recurse", recurse),
+		wasmtest.Export("noresult", wasmtest.NewFunction(i32, nil, nil)),
+	)
+
+	call := func(name string, n int32) Optional[Int32] {
+		return wasmtest.Call[Optional[Int32]](sum, ctx, module, new(instance), wasmtest.Bytes(name), Int32(n))
+	}
+	assertEqual(t, Res(Int32(0+1+4+9)), call("square", 4))
+	// Callbacks may call host functions calling other callbacks, but cannot
+	// call themselves recursively.
+	assertEqual(t, Res(Int32(0+0+(0+1))), call("reenter", 3))
+	assertEqual(t, Err[Int32](EBUSY), call("recurse", 1))
+	assertEqual(t, Err[Int32](EINVAL), call("noresult", 1))
+	assertEqual(t, Err[Int32](ENOENT), call("missing", 1))
+}
+
+func TestFuncCallback64(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.Write(0, []byte("get"))
+	memory.WriteUint32Le(64, 42)
+	ctx := context.Background()
+
+	fn := Memory64[*instance]().Decorate("test", F1(func(this *instance, ctx context.Context, cb Callback0[Pointer[Uint32]]) Optional[Uint32] {
+		p, err := cb.Call(ctx)
+		if err != nil {
+			return Err[Uint32](err)
+		}
+		return Res(p.Load())
+	}))
+
+	i64 := []api.ValueType{api.ValueTypeI64}
+	get := wasmtest.NewFunction(nil, i64, func(ctx context.Context, params ...uint64) ([]uint64, error) {
+		return []uint64{64}, nil
+	})
+	module := wasmtest.NewModule("test", wasmtest.Memory(memory), wasmtest.Export("get", get))
+
+	stack := []uint64{0, 3}
+	fn.Func(new(instance), ctx, module, stack)
+	assertEqual(t, []uint64{42, 0}, stack)
+}
+
 func testFunc(t *testing.T, opts []Option[*instance], test func(*instance, context.Context, api.Module)) {
 	t.Helper()
 	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
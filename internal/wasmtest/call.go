@@ -9,15 +9,14 @@ import (
 )
 
 func Call[R types.Param[R], T any](fn wazergo.Function[T], ctx context.Context, module api.Module, this T, args ...types.Result) (ret R) {
-	malloc = 0
-
 	stack := make([]uint64, max(fn.NumParams(), fn.NumResults()))
 	memory := module.Memory()
 	offset := 0
+	callMemory := &callMemory{Memory: memory}
 
 	for _, arg := range args {
-		arg.StoreValue(memory, stack[offset:])
-		offset += len(arg.ValueTypes())
+		arg.StoreValue(callMemory, stack[offset:])
+		offset += types.StackSize(arg.ValueTypes())
 	}
 
 	fn.Func(this, ctx, module, stack)
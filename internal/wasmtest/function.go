@@ -0,0 +1,53 @@
+package wasmtest
+
+import (
+	"context"
+
+	"github.com/stealthrocket/wazergo/types"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Function is an implementation of wazero's api.Function interface intended to
+// be used as a stub for functions exported by a Module.
+type Function struct {
+	api.Function // TODO: implement more features of the interface
+	definition   functionDefinition
+	call         func(context.Context, ...uint64) ([]uint64, error)
+}
+
+// NewFunction constructs a Function with the given signature, which calls fn
+// when invoked.
+func NewFunction(params, results []api.ValueType, fn func(ctx context.Context, params ...uint64) ([]uint64, error)) *Function {
+	return &Function{
+		definition: functionDefinition{params: params, results: results},
+		call:       fn,
+	}
+}
+
+func (f *Function) Definition() api.FunctionDefinition {
+	return &f.definition
+}
+
+func (f *Function) Call(ctx context.Context, params ...uint64) ([]uint64, error) {
+	return f.call(ctx, params...)
+}
+
+func (f *Function) CallWithStack(ctx context.Context, stack []uint64) error {
+	ret, err := f.call(ctx, stack[:types.StackSize(f.definition.params)]...)
+	copy(stack, ret)
+	return err
+}
+
+type functionDefinition struct {
+	api.FunctionDefinition // TODO: implement more features of the interface
+	params                 []api.ValueType
+	results                []api.ValueType
+}
+
+func (def *functionDefinition) ParamTypes() []api.ValueType {
+	return def.params
+}
+
+func (def *functionDefinition) ResultTypes() []api.ValueType {
+	return def.results
+}
@@ -12,6 +12,7 @@ type Module struct {
 	api.Module // TODO: implement more features of the interface
 	name       string
 	memory     moduleMemory
+	functions  map[string]api.Function
 }
 
 // ModuleOption represents configuration options for the Module type.
@@ -22,6 +23,16 @@ func Memory(memory api.Memory) ModuleOption {
 	return wazergo.OptionFunc(func(module *Module) { module.memory.Memory = memory })
 }
 
+// Export adds a function exported by a Module instance.
+func Export(name string, fn api.Function) ModuleOption {
+	return wazergo.OptionFunc(func(module *Module) {
+		if module.functions == nil {
+			module.functions = make(map[string]api.Function)
+		}
+		module.functions[name] = fn
+	})
+}
+
 // NewModule constructs a Module instance with the given name and configuration
 // options.
 func NewModule(name string, opts ...ModuleOption) *Module {
@@ -35,6 +46,10 @@ func (mod *Module) Name() string { return mod.name }
 
 func (mod *Module) Memory() api.Memory { return &mod.memory }
 
+func (mod *Module) ExportedFunction(name string) api.Function {
+	return mod.functions[name]
+}
+
 func (mod *Module) ExportedMemory(name string) api.Memory {
 	switch name {
 	case "memory":
@@ -8,16 +8,25 @@ import (
 	"github.com/tetratelabs/wazero/api"
 )
 
-var malloc uint32
-
-func brk(memory api.Memory, offset, length uint32) ([]byte, uint32) {
-	b := wasm.Read(memory, offset, length)
-	malloc = offset + length
-	return b, offset
+// callMemory is the memory passed to the StoreValue method of arguments by
+// Call, carrying the allocator used to lay out the values in memory.
+type callMemory struct {
+	api.Memory
+	alloc *wasm.Allocator
 }
 
+// sbrk allocates size bytes in the memory passed to StoreValue by Call. The
+// function panics if memory was not, since allocating from a new allocator
+// would overwrite the values previously stored in memory.
 func sbrk(memory api.Memory, size uint32) ([]byte, uint32) {
-	return brk(memory, malloc, size)
+	m, ok := memory.(*callMemory)
+	if !ok {
+		panic("wasmtest: values can only be stored in memory by Call")
+	}
+	if m.alloc == nil {
+		m.alloc = wasm.NewAllocator(m.Memory, 0, m.Memory.Size())
+	}
+	return m.alloc.Bytes(size, 1)
 }
 
 // Bytes is an extension of the types.Bytes type which adds the ability to treat
@@ -46,3 +55,7 @@ func (arg Bytes) StoreValue(memory api.Memory, stack []uint64) {
 func (arg Bytes) ValueTypes() []api.ValueType {
 	return types.Bytes(arg).ValueTypes()
 }
+
+func (arg Bytes) ValueTypes64() []api.ValueType {
+	return types.Bytes(arg).ValueTypes64()
+}
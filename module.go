@@ -2,6 +2,7 @@ package wazergo
 
 import (
 	"context"
+	"errors"
 
 	. "github.com/stealthrocket/wazergo/types"
 	"github.com/tetratelabs/wazero"
@@ -128,7 +129,34 @@ func (c *CompiledModule[T]) Instantiate(ctx context.Context, options ...Option[T
 	if err != nil {
 		return nil, err
 	}
-	return &ModuleInstance[T]{module, moduleName, instance}, nil
+	tables := handleTables(instance, c.HostModule.Functions())
+	return &ModuleInstance[T]{module, moduleName, instance, tables}, nil
+}
+
+// handleTables returns the list of handle tables that the module instance
+// declares by implementing types.HandleTableOwner, and that the parameters and
+// results of functions resolve handles from on the module instance.
+func handleTables[T Module](instance T, functions Functions[T]) (tables []api.Closer) {
+	seen := make(map[api.Closer]bool)
+	if owner, ok := any(instance).(HandleTableOwner); ok {
+		for _, t := range owner.HandleTables() {
+			if !seen[t] {
+				seen[t] = true
+				tables = append(tables, t)
+			}
+		}
+	}
+	for _, fn := range functions {
+		for _, values := range [][]Value{fn.Params, fn.Results} {
+			for _, v := range values {
+				if t := HandleTableOf(instance, v); t != nil && !seen[t] {
+					seen[t] = true
+					tables = append(tables, t)
+				}
+			}
+		}
+	}
+	return tables
 }
 
 // ModuleInstance represents a module instance created from a compiled host module.
@@ -136,6 +164,8 @@ type ModuleInstance[T Module] struct {
 	api.Module
 	moduleName string
 	instance   T
+	// Handle tables of the instance, which are closed after the instance.
+	tables []api.Closer
 }
 
 func (m *ModuleInstance[T]) String() string {
@@ -153,8 +183,15 @@ func (m *ModuleInstance[T]) ExportedFunction(name string) api.Function {
 	return nil
 }
 
+// Close closes the module instance, then the tables of handles that it owns
+// (see types.HandleTableOwner) or exposes to the parameters and results of its
+// functions (see types.HandleValue).
 func (m *ModuleInstance[T]) Close(ctx context.Context) error {
-	return m.instance.Close(ctx)
+	errs := []error{m.instance.Close(ctx)}
+	for _, t := range m.tables {
+		errs = append(errs, t.Close(ctx))
+	}
+	return errors.Join(errs...)
 }
 
 func (m *ModuleInstance[T]) CloseWithExitCode(ctx context.Context, _ uint32) error {
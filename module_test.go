@@ -13,6 +13,7 @@ import (
 
 var hostModule wazergo.HostModule[*hostInstance] = hostFunctions{
 	"answer": wazergo.F0((*hostInstance).Answer),
+	"swap":   wazergo.F1((*hostInstance).Swap),
 }
 
 type hostFunctions wazergo.Functions[*hostInstance]
@@ -43,6 +44,10 @@ func (m *hostInstance) Answer(ctx context.Context) Int32 {
 	return Int32(m.answer)
 }
 
+func (m *hostInstance) Swap(ctx context.Context, v V128) V128 {
+	return MakeV128(v.Hi(), v.Lo())
+}
+
 func answer(a int) wazergo.Option[*hostInstance] {
 	return wazergo.OptionFunc(func(m *hostInstance) { m.answer = a })
 }
@@ -84,6 +89,95 @@ func TestMultipleHostModuleInstances(t *testing.T) {
 	}
 }
 
+func TestHostFunctionV128(t *testing.T) {
+	ctx := context.Background()
+
+	// The compiler engine does not support calling host functions directly.
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter())
+	defer runtime.Close(ctx)
+
+	instance := wazergo.MustInstantiate(ctx, runtime, hostModule)
+	defer instance.Close(ctx)
+
+	swap := runtime.Module("test").ExportedFunction("swap")
+	assertEqual(t, []api.ValueType{ValueTypeV128}, swap.Definition().ParamTypes())
+	assertEqual(t, []api.ValueType{ValueTypeV128}, swap.Definition().ResultTypes())
+
+	ret, err := swap.Call(wazergo.WithModuleInstance(ctx, instance), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, []uint64{2, 1}, ret)
+}
+
+type file struct{ closed bool }
+
+func (f *file) Close() error { f.closed = true; return nil }
+
+type fileFunctions wazergo.Functions[*fileInstance]
+
+func (m fileFunctions) Name() string { return "files" }
+
+func (m fileFunctions) Functions() wazergo.Functions[*fileInstance] {
+	return (wazergo.Functions[*fileInstance](m))
+}
+
+func (m fileFunctions) Instantiate(ctx context.Context, opts ...wazergo.Option[*fileInstance]) (*fileInstance, error) {
+	ins := new(fileInstance)
+	wazergo.Configure(ins, opts...)
+	return ins, nil
+}
+
+type fileInstance struct {
+	files HandleTable[*file]
+	locks HandleTable[*file]
+}
+
+func (m *fileInstance) Handles() *HandleTable[*file] { return &m.files }
+
+func (m *fileInstance) HandleTables() []api.Closer {
+	return []api.Closer{&m.files, &m.locks}
+}
+
+func (m *fileInstance) Close(ctx context.Context) error { return nil }
+
+func (m *fileInstance) Open(ctx context.Context) Optional[Handle[*file]] {
+	return Res(m.files.Insert(new(file)))
+}
+
+func TestCloseHandleTables(t *testing.T) {
+	ctx := context.Background()
+
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+
+	var fileModule wazergo.HostModule[*fileInstance] = fileFunctions{
+		"open": wazergo.F0((*fileInstance).Open),
+	}
+
+	var this *fileInstance
+	instance := wazergo.MustInstantiate(ctx, runtime, fileModule,
+		wazergo.OptionFunc(func(m *fileInstance) { this = m }),
+	)
+
+	f0, f1, f2 := new(file), new(file), new(file)
+	this.files.Insert(f0)
+	this.files.Insert(f1)
+	this.locks.Insert(f2)
+
+	// The module instance does not close its tables, the files table is closed
+	// because it is exposed to the result of the open function, and the locks
+	// table because the instance declares it.
+	if err := instance.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, true, f0.closed)
+	assertEqual(t, true, f1.closed)
+	assertEqual(t, true, f2.closed)
+	assertEqual(t, 0, this.files.Len())
+	assertEqual(t, 0, this.locks.Len())
+}
+
 func loadModule(ctx context.Context, runtime wazero.Runtime, filePath string) (api.Module, error) {
 	b, err := os.ReadFile(filePath)
 	if err != nil {
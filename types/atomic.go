@@ -0,0 +1,227 @@
+//go:build 386 || amd64 || arm || arm64 || loong64 || mips64le || mipsle || ppc64le || riscv64 || wasm
+
+package types
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// AtomicInteger is a type constraint matching the integer types that support
+// atomic operations in WebAssembly memory.
+type AtomicInteger[T any] interface {
+	Object[T]
+	Int32 | Uint32 | Int64 | Uint64
+}
+
+// The atomic operations of this file are used to coordinate with guests using
+// the threads proposal, where the memory is shared between multiple threads
+// executing guest code. Like the atomic instructions of WebAssembly, they
+// require the pointer to be aligned on the size of the integer, and panic if
+// it is not.
+//
+// The operations map the integers of the WebAssembly memory to Go integers,
+// which is only correct on little-endian hosts (e.g. amd64 and arm64). They are
+// not defined on big-endian architectures, where programs using them fail to
+// compile instead of silently operating on byte-swapped values.
+//
+// The operations are only atomic with respect to each other and to the atomic
+// instructions of guests if the memory is never moved. Memories declared as
+// shared by the guest module cannot be moved when they grow, but nothing makes
+// a wasm.Memory shared: its Grow method reallocates the memory, so updates made
+// concurrently to growing such a memory may be lost. Programs using wasm.Memory
+// values with the atomic operations must not grow them concurrently.
+
+// AtomicLoad atomically loads the integer that ptr points to.
+func AtomicLoad[T AtomicInteger[T]](ptr Pointer[T]) T {
+	p := atomicPointer(ptr)
+	switch unsafe.Sizeof(T(0)) {
+	case 4:
+		return T(atomic.LoadUint32((*uint32)(p)))
+	default:
+		return T(atomic.LoadUint64((*uint64)(p)))
+	}
+}
+
+// AtomicStore atomically stores value to the integer that ptr points to.
+func AtomicStore[T AtomicInteger[T]](ptr Pointer[T], value T) {
+	p := atomicPointer(ptr)
+	switch unsafe.Sizeof(value) {
+	case 4:
+		atomic.StoreUint32((*uint32)(p), uint32(value))
+	default:
+		atomic.StoreUint64((*uint64)(p), uint64(value))
+	}
+}
+
+// AtomicSwap atomically stores value to the integer that ptr points to and
+// returns the previous value.
+func AtomicSwap[T AtomicInteger[T]](ptr Pointer[T], value T) T {
+	p := atomicPointer(ptr)
+	switch unsafe.Sizeof(value) {
+	case 4:
+		return T(atomic.SwapUint32((*uint32)(p), uint32(value)))
+	default:
+		return T(atomic.SwapUint64((*uint64)(p), uint64(value)))
+	}
+}
+
+// AtomicAdd atomically adds delta to the integer that ptr points to and
+// returns the new value.
+func AtomicAdd[T AtomicInteger[T]](ptr Pointer[T], delta T) T {
+	p := atomicPointer(ptr)
+	switch unsafe.Sizeof(delta) {
+	case 4:
+		return T(atomic.AddUint32((*uint32)(p), uint32(delta)))
+	default:
+		return T(atomic.AddUint64((*uint64)(p), uint64(delta)))
+	}
+}
+
+// AtomicCompareAndSwap atomically stores value to the integer that ptr points
+// to if it is equal to old, and returns whether the swap happened.
+func AtomicCompareAndSwap[T AtomicInteger[T]](ptr Pointer[T], old, value T) bool {
+	p := atomicPointer(ptr)
+	switch unsafe.Sizeof(value) {
+	case 4:
+		return atomic.CompareAndSwapUint32((*uint32)(p), uint32(old), uint32(value))
+	default:
+		return atomic.CompareAndSwapUint64((*uint64)(p), uint64(old), uint64(value))
+	}
+}
+
+func atomicPointer[T AtomicInteger[T]](ptr Pointer[T]) unsafe.Pointer {
+	size := uint32(unsafe.Sizeof(T(0)))
+	if ptr.offset%size != 0 {
+		panic(fmt.Sprintf("unaligned atomic access: @%08x/%d", ptr.offset, size))
+	}
+	return unsafe.Pointer(&ptr.Object()[0])
+}
+
+// WaitResult is the result of AtomicWait, with the values of the result of the
+// memory.atomic.wait instructions of WebAssembly.
+type WaitResult = Int32
+
+const (
+	WaitOK       WaitResult = 0 // woken by AtomicNotify
+	WaitNotEqual WaitResult = 1 // the integer was not equal to the expected value
+	WaitTimedOut WaitResult = 2 // the timeout expired before being woken
+)
+
+// AtomicWait blocks until AtomicNotify is called with the same pointer, or the
+// timeout expires. A negative timeout means waiting forever. The function
+// returns WaitNotEqual immediately if the integer that ptr points to is not
+// equal to expected.
+//
+// Host functions use AtomicWait and AtomicNotify to implement the equivalent
+// of the memory.atomic.wait and memory.atomic.notify instructions, for example
+// to let guest threads block on a futex. If ctx is canceled before the wait
+// completes, the function returns WaitTimedOut and the context error.
+//
+// The queue of waiters is maintained by the host, and shared by all the
+// memories of the process. Only guests calling host functions which use
+// AtomicWait and AtomicNotify take part in it: the memory.atomic.wait and
+// memory.atomic.notify instructions executed by guests use the queue of the
+// WebAssembly runtime, and do not wake or get woken by the host functions.
+func AtomicWait[T AtomicInteger[T]](ctx context.Context, ptr Pointer[T], expected T, timeout time.Duration) (WaitResult, error) {
+	key := waitKey{ptr.memory, ptr.offset}
+	wake := make(chan struct{})
+
+	waiters.mutex.Lock()
+	if AtomicLoad(ptr) != expected {
+		waiters.mutex.Unlock()
+		return WaitNotEqual, nil
+	}
+	if waiters.queues == nil {
+		waiters.queues = make(map[waitKey][]chan struct{})
+	}
+	waiters.queues[key] = append(waiters.queues[key], wake)
+	waiters.mutex.Unlock()
+
+	var expire <-chan time.Time
+	if timeout >= 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expire = t.C
+	}
+
+	var err error
+	select {
+	case <-wake:
+		return WaitOK, nil
+	case <-expire:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if !waiters.remove(key, wake) {
+		// AtomicNotify woke the waiter concurrently to the timeout.
+		return WaitOK, nil
+	}
+	return WaitTimedOut, err
+}
+
+// AtomicNotify wakes up to count goroutines blocked in AtomicWait on ptr, in
+// the order they started waiting, and returns the number of goroutines woken.
+// Guests blocked in memory.atomic.wait instructions are not woken (see
+// AtomicWait).
+func AtomicNotify[T AtomicInteger[T]](ptr Pointer[T], count uint32) uint32 {
+	key := waitKey{ptr.memory, ptr.offset}
+
+	waiters.mutex.Lock()
+	defer waiters.mutex.Unlock()
+
+	queue := waiters.queues[key]
+	n := uint32(len(queue))
+	if n > count {
+		n = count
+	}
+	for _, wake := range queue[:n] {
+		close(wake)
+	}
+	if queue = queue[n:]; len(queue) == 0 {
+		delete(waiters.queues, key)
+	} else {
+		waiters.queues[key] = queue
+	}
+	return n
+}
+
+type waitKey struct {
+	memory api.Memory
+	offset uint32
+}
+
+// waitQueues is the table of goroutines blocked in AtomicWait, indexed by the
+// memory location that they are waiting on.
+type waitQueues struct {
+	mutex  sync.Mutex
+	queues map[waitKey][]chan struct{}
+}
+
+func (w *waitQueues) remove(key waitKey, wake chan struct{}) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	queue := w.queues[key]
+	for i, c := range queue {
+		if c == wake {
+			queue = append(queue[:i:i], queue[i+1:]...)
+			if len(queue) == 0 {
+				delete(w.queues, key)
+			} else {
+				w.queues[key] = queue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+var waiters waitQueues
@@ -0,0 +1,88 @@
+//go:build 386 || amd64 || arm || arm64 || loong64 || mips64le || mipsle || ppc64le || riscv64 || wasm
+
+package types_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/stealthrocket/wazergo/types"
+	"github.com/stealthrocket/wazergo/wasm"
+)
+
+func TestAtomic(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	p32 := Ptr[Uint32](memory, 8)
+	p64 := Ptr[Int64](memory, 16)
+
+	AtomicStore(p32, 40)
+	assertEqual(t, AtomicAdd(p32, 2), Uint32(42))
+	assertEqual(t, AtomicCompareAndSwap(p32, 41, 0), false)
+	assertEqual(t, AtomicCompareAndSwap(p32, 42, 1), true)
+	assertEqual(t, AtomicSwap(p32, 2), Uint32(1))
+	assertEqual(t, AtomicLoad(p32), Uint32(2))
+
+	AtomicStore(p64, -1)
+	assertEqual(t, AtomicAdd(p64, -1), Int64(-2))
+	assertEqual(t, p64.Load(), Int64(-2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			AtomicAdd(p64, 1)
+		}()
+	}
+	wg.Wait()
+	assertEqual(t, AtomicLoad(p64), Int64(98))
+
+	assertPanic(t, func() { AtomicLoad(Ptr[Uint64](memory, 4)) })
+	assertSegfault(t, wasm.SEGFAULT{Offset: wasm.PageSize, Length: 4}, func() {
+		AtomicLoad(Ptr[Int32](memory, wasm.PageSize))
+	})
+}
+
+func TestAtomicWait(t *testing.T) {
+	ctx := context.Background()
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	ptr := Ptr[Int32](memory, 0)
+
+	res, err := AtomicWait(ctx, ptr, 1, -1)
+	assertEqual(t, res, WaitNotEqual)
+	assertEqual(t, err, nil)
+
+	res, err = AtomicWait(ctx, ptr, 0, time.Millisecond)
+	assertEqual(t, res, WaitTimedOut)
+	assertEqual(t, err, nil)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	res, err = AtomicWait(canceled, ptr, 0, -1)
+	assertEqual(t, res, WaitTimedOut)
+	assertEqual(t, err, context.Canceled)
+
+	results := make(chan WaitResult)
+	for i := 0; i < 3; i++ {
+		go func() {
+			res, _ := AtomicWait(ctx, ptr, 0, -1)
+			results <- res
+		}()
+	}
+
+	woken := uint32(0)
+	for woken < 2 {
+		woken += AtomicNotify(ptr, 2-woken)
+		time.Sleep(time.Millisecond)
+	}
+	assertEqual(t, <-results, WaitOK)
+	assertEqual(t, <-results, WaitOK)
+
+	for AtomicNotify(ptr, 10) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assertEqual(t, <-results, WaitOK)
+	assertEqual(t, AtomicNotify(ptr, 10), uint32(0))
+}
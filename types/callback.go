@@ -0,0 +1,259 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Callback0 is a parameter type representing a guest function accepting no
+// parameters and returning a result of type R, which host functions can call
+// back into.
+//
+// Callbacks are references to functions exported by the guest, passed by name
+// as ExportRef values. The parameters are encoded with their StoreValue method,
+// and the results decoded with their LoadValue method, so callbacks accept the
+// same types as the parameters and results of host functions. Functions which
+// return nothing use None as result type.
+//
+// The signature of the guest function is verified when the callback is called,
+// the call fails with EINVAL if the signature does not match the one of the
+// callback in either 32 or 64 bits addressing mode. Calling null callbacks or
+// callbacks to functions that the guest does not export fails with the error
+// reported by ExportRef.Function.
+//
+// Callbacks are not recursive: if the guest function calls a host function
+// which attempts to call the same guest function again, the call fails with
+// EBUSY. Guest functions are matched by name, calling other guest functions
+// is permitted, including ones which call back into the host function that
+// is already running. Host functions which must not be re-entered need to
+// protect their state themselves.
+//
+// Note that calling a guest function may grow its memory, which invalidates
+// byte slices previously loaded from memory (e.g. Bytes or String values).
+type Callback0[R Param[R]] struct{ callback }
+
+// Call calls the guest function, passing ctx to the guest. The context must be
+// the one that the host function was called with.
+func (cb Callback0[R]) Call(ctx context.Context) (ret R, err error) {
+	stack, err := cb.call(ctx, nil, ret)
+	if err == nil {
+		ret = ret.LoadValue(cb.memory, stack)
+	}
+	return ret, err
+}
+
+func (cb Callback0[R]) LoadValue(memory api.Memory, stack []uint64) Callback0[R] {
+	return Callback0[R]{cb.load(memory, stack)}
+}
+
+func (cb Callback0[R]) LoadContextValue(ctx context.Context, this any, module api.Module, stack []uint64) Callback0[R] {
+	return Callback0[R]{cb.loadContext(ctx, this, module, stack)}
+}
+
+func (cb Callback0[R]) ValueTypes64() []api.ValueType {
+	var ret R
+	return cb.valueTypes64(ret)
+}
+
+// Callback1 is like Callback0 but for guest functions accepting one parameter.
+type Callback1[P Result, R Param[R]] struct{ callback }
+
+// Call calls the guest function, passing ctx to the guest. The context must be
+// the one that the host function was called with.
+func (cb Callback1[P, R]) Call(ctx context.Context, p P) (ret R, err error) {
+	stack, err := cb.call(ctx, []Result{p}, ret)
+	if err == nil {
+		ret = ret.LoadValue(cb.memory, stack)
+	}
+	return ret, err
+}
+
+func (cb Callback1[P, R]) LoadValue(memory api.Memory, stack []uint64) Callback1[P, R] {
+	return Callback1[P, R]{cb.load(memory, stack)}
+}
+
+func (cb Callback1[P, R]) LoadContextValue(ctx context.Context, this any, module api.Module, stack []uint64) Callback1[P, R] {
+	return Callback1[P, R]{cb.loadContext(ctx, this, module, stack)}
+}
+
+func (cb Callback1[P, R]) ValueTypes64() []api.ValueType {
+	var p P
+	var ret R
+	return cb.valueTypes64(p, ret)
+}
+
+// Callback2 is like Callback0 but for guest functions accepting two parameters.
+type Callback2[P1, P2 Result, R Param[R]] struct{ callback }
+
+// Call calls the guest function, passing ctx to the guest. The context must be
+// the one that the host function was called with.
+func (cb Callback2[P1, P2, R]) Call(ctx context.Context, p1 P1, p2 P2) (ret R, err error) {
+	stack, err := cb.call(ctx, []Result{p1, p2}, ret)
+	if err == nil {
+		ret = ret.LoadValue(cb.memory, stack)
+	}
+	return ret, err
+}
+
+func (cb Callback2[P1, P2, R]) LoadValue(memory api.Memory, stack []uint64) Callback2[P1, P2, R] {
+	return Callback2[P1, P2, R]{cb.load(memory, stack)}
+}
+
+func (cb Callback2[P1, P2, R]) LoadContextValue(ctx context.Context, this any, module api.Module, stack []uint64) Callback2[P1, P2, R] {
+	return Callback2[P1, P2, R]{cb.loadContext(ctx, this, module, stack)}
+}
+
+func (cb Callback2[P1, P2, R]) ValueTypes64() []api.ValueType {
+	var p1 P1
+	var p2 P2
+	var ret R
+	return cb.valueTypes64(p1, p2, ret)
+}
+
+// Callback3 is like Callback0 but for guest functions accepting three
+// parameters.
+type Callback3[P1, P2, P3 Result, R Param[R]] struct{ callback }
+
+// Call calls the guest function, passing ctx to the guest. The context must be
+// the one that the host function was called with.
+func (cb Callback3[P1, P2, P3, R]) Call(ctx context.Context, p1 P1, p2 P2, p3 P3) (ret R, err error) {
+	stack, err := cb.call(ctx, []Result{p1, p2, p3}, ret)
+	if err == nil {
+		ret = ret.LoadValue(cb.memory, stack)
+	}
+	return ret, err
+}
+
+func (cb Callback3[P1, P2, P3, R]) LoadValue(memory api.Memory, stack []uint64) Callback3[P1, P2, P3, R] {
+	return Callback3[P1, P2, P3, R]{cb.load(memory, stack)}
+}
+
+func (cb Callback3[P1, P2, P3, R]) LoadContextValue(ctx context.Context, this any, module api.Module, stack []uint64) Callback3[P1, P2, P3, R] {
+	return Callback3[P1, P2, P3, R]{cb.loadContext(ctx, this, module, stack)}
+}
+
+func (cb Callback3[P1, P2, P3, R]) ValueTypes64() []api.ValueType {
+	var p1 P1
+	var p2 P2
+	var p3 P3
+	var ret R
+	return cb.valueTypes64(p1, p2, p3, ret)
+}
+
+var (
+	_ ContextParam[Callback0[None]]                      = Callback0[None]{}
+	_ ContextParam[Callback1[Int32, None]]               = Callback1[Int32, None]{}
+	_ ContextParam[Callback2[Int32, Int32, None]]        = Callback2[Int32, Int32, None]{}
+	_ ContextParam[Callback3[Int32, Int32, Int32, None]] = Callback3[Int32, Int32, Int32, None]{}
+	_ Formatter                                          = Callback0[None]{}
+	_ Value64                                            = Callback0[None]{}
+)
+
+// callback is the implementation shared by the CallbackN types.
+type callback struct {
+	ref    ExportRef
+	memory api.Memory
+}
+
+// callbackKey is the context key marking calls made from a callback, the value
+// is the callbackFrame of the guest function being called.
+type callbackKey struct{}
+
+// callbackFrame is the list of guest functions being called by callbacks, from
+// the most recent to the least recent.
+type callbackFrame struct {
+	name   string
+	caller *callbackFrame
+}
+
+// ExportRef returns the reference to the guest function of the callback.
+func (cb callback) ExportRef() ExportRef {
+	return cb.ref
+}
+
+func (cb callback) Format(w io.Writer) {
+	cb.ref.Format(w)
+}
+
+func (cb callback) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	cb.ref.FormatValue(w, memory, stack)
+}
+
+func (cb callback) ValueTypes() []api.ValueType {
+	return cb.ref.ValueTypes()
+}
+
+// valueTypes64 returns the value types of the callback in 64 bits addressing
+// mode, or nil if the parameters or results of the guest function are not
+// supported in this mode.
+func (cb callback) valueTypes64(values ...Value) []api.ValueType {
+	for _, v := range values {
+		if !Supports64(v) {
+			return nil
+		}
+	}
+	return cb.ref.ValueTypes64()
+}
+
+func (cb callback) load(memory api.Memory, stack []uint64) callback {
+	return callback{ref: cb.ref.LoadValue(memory, stack), memory: memory}
+}
+
+func (cb callback) loadContext(ctx context.Context, this any, module api.Module, stack []uint64) callback {
+	return callback{ref: cb.ref.LoadContextValue(ctx, this, module, stack), memory: module.Memory()}
+}
+
+func (cb callback) call(ctx context.Context, params []Result, ret Value) ([]uint64, error) {
+	fn, err := cb.ref.Function()
+	if err != nil {
+		return nil, fmt.Errorf("callback %q: %w", cb.ref.name, err)
+	}
+	caller, _ := ctx.Value(callbackKey{}).(*callbackFrame)
+	for f := caller; f != nil; f = f.caller {
+		if f.name == cb.ref.name {
+			return nil, fmt.Errorf("callback %q: recursive call: %w", cb.ref.name, EBUSY)
+		}
+	}
+	if !signatureMatches(fn.Definition(), params, ret) {
+		return nil, fmt.Errorf("callback %q: function signature mismatch: %w", cb.ref.name, EINVAL)
+	}
+
+	size, results := 0, StackSize(ret.ValueTypes())
+	for _, p := range params {
+		size += StackSize(p.ValueTypes())
+	}
+	if results > size {
+		size = results
+	}
+	stack := make([]uint64, size)
+	offset := 0
+	for _, p := range params {
+		n := StackSize(p.ValueTypes()) + offset
+		p.StoreValue(cb.memory, stack[offset:n:n])
+		offset = n
+	}
+
+	ctx = context.WithValue(ctx, callbackKey{}, &callbackFrame{cb.ref.name, caller})
+	return stack, fn.CallWithStack(ctx, stack)
+}
+
+// signatureMatches returns true if the signature of the guest function matches
+// the parameters and result of a callback, in either 32 or 64 bits addressing
+// mode.
+func signatureMatches(def api.FunctionDefinition, params []Result, ret Value) bool {
+	var params32, params64 []api.ValueType
+	supports64 := Supports64(ret)
+	for _, p := range params {
+		params32 = append(params32, p.ValueTypes()...)
+		params64 = append(params64, ValueTypes64(p)...)
+		supports64 = supports64 && Supports64(p)
+	}
+	if bytes.Equal(def.ParamTypes(), params32) && bytes.Equal(def.ResultTypes(), ret.ValueTypes()) {
+		return true
+	}
+	return supports64 && bytes.Equal(def.ParamTypes(), params64) && bytes.Equal(def.ResultTypes(), ValueTypes64(ret))
+}
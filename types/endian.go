@@ -0,0 +1,265 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// The types declared in this file represent integers stored in memory in
+// big-endian byte order, also known as network byte order, which is commonly
+// used in packet headers and protocol structures:
+//
+//	type ipv4Header struct {
+//		VersionIHL  Uint8
+//		TOS         Uint8
+//		TotalLength Uint16BE
+//		...
+//	}
+//
+// The types are byte arrays rather than integers so their representation in Go
+// is the same as in memory, which allows them to be used as fields of struct
+// types loaded with UnsafeLoadObject. The integer values are obtained with the
+// methods named after the Go integer types (e.g. Uint16BE.Uint16).
+
+// Int16BE is an object type representing int16 values stored in big-endian
+// byte order.
+type Int16BE [2]byte
+
+// MakeInt16BE constructs an Int16BE value from x.
+func MakeInt16BE(x int16) (v Int16BE) {
+	binary.BigEndian.PutUint16(v[:], uint16(x))
+	return v
+}
+
+// Int16 returns the value of arg.
+func (arg Int16BE) Int16() int16 {
+	return int16(binary.BigEndian.Uint16(arg[:]))
+}
+
+func (arg Int16BE) Format(w io.Writer) {
+	fmt.Fprintf(w, "%d", arg.Int16())
+}
+
+func (arg Int16BE) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Int16BE) LoadObject(memory api.Memory, object []byte) Int16BE {
+	return Int16BE(object[:2])
+}
+
+func (arg Int16BE) StoreObject(memory api.Memory, object []byte) {
+	copy(object, arg[:])
+}
+
+func (arg Int16BE) ObjectSize() int {
+	return 2
+}
+
+var (
+	_ Object[Int16BE] = Int16BE{}
+	_ Formatter       = Int16BE{}
+)
+
+// Int32BE is an object type representing int32 values stored in big-endian
+// byte order.
+type Int32BE [4]byte
+
+// MakeInt32BE constructs an Int32BE value from x.
+func MakeInt32BE(x int32) (v Int32BE) {
+	binary.BigEndian.PutUint32(v[:], uint32(x))
+	return v
+}
+
+// Int32 returns the value of arg.
+func (arg Int32BE) Int32() int32 {
+	return int32(binary.BigEndian.Uint32(arg[:]))
+}
+
+func (arg Int32BE) Format(w io.Writer) {
+	fmt.Fprintf(w, "%d", arg.Int32())
+}
+
+func (arg Int32BE) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Int32BE) LoadObject(memory api.Memory, object []byte) Int32BE {
+	return Int32BE(object[:4])
+}
+
+func (arg Int32BE) StoreObject(memory api.Memory, object []byte) {
+	copy(object, arg[:])
+}
+
+func (arg Int32BE) ObjectSize() int {
+	return 4
+}
+
+var (
+	_ Object[Int32BE] = Int32BE{}
+	_ Formatter       = Int32BE{}
+)
+
+// Int64BE is an object type representing int64 values stored in big-endian
+// byte order.
+type Int64BE [8]byte
+
+// MakeInt64BE constructs an Int64BE value from x.
+func MakeInt64BE(x int64) (v Int64BE) {
+	binary.BigEndian.PutUint64(v[:], uint64(x))
+	return v
+}
+
+// Int64 returns the value of arg.
+func (arg Int64BE) Int64() int64 {
+	return int64(binary.BigEndian.Uint64(arg[:]))
+}
+
+func (arg Int64BE) Format(w io.Writer) {
+	fmt.Fprintf(w, "%d", arg.Int64())
+}
+
+func (arg Int64BE) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Int64BE) LoadObject(memory api.Memory, object []byte) Int64BE {
+	return Int64BE(object[:8])
+}
+
+func (arg Int64BE) StoreObject(memory api.Memory, object []byte) {
+	copy(object, arg[:])
+}
+
+func (arg Int64BE) ObjectSize() int {
+	return 8
+}
+
+var (
+	_ Object[Int64BE] = Int64BE{}
+	_ Formatter       = Int64BE{}
+)
+
+// Uint16BE is an object type representing uint16 values stored in big-endian
+// byte order.
+type Uint16BE [2]byte
+
+// MakeUint16BE constructs a Uint16BE value from x.
+func MakeUint16BE(x uint16) (v Uint16BE) {
+	binary.BigEndian.PutUint16(v[:], x)
+	return v
+}
+
+// Uint16 returns the value of arg.
+func (arg Uint16BE) Uint16() uint16 {
+	return uint16(binary.BigEndian.Uint16(arg[:]))
+}
+
+func (arg Uint16BE) Format(w io.Writer) {
+	fmt.Fprintf(w, "%d", arg.Uint16())
+}
+
+func (arg Uint16BE) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Uint16BE) LoadObject(memory api.Memory, object []byte) Uint16BE {
+	return Uint16BE(object[:2])
+}
+
+func (arg Uint16BE) StoreObject(memory api.Memory, object []byte) {
+	copy(object, arg[:])
+}
+
+func (arg Uint16BE) ObjectSize() int {
+	return 2
+}
+
+var (
+	_ Object[Uint16BE] = Uint16BE{}
+	_ Formatter        = Uint16BE{}
+)
+
+// Uint32BE is an object type representing uint32 values stored in big-endian
+// byte order.
+type Uint32BE [4]byte
+
+// MakeUint32BE constructs a Uint32BE value from x.
+func MakeUint32BE(x uint32) (v Uint32BE) {
+	binary.BigEndian.PutUint32(v[:], x)
+	return v
+}
+
+// Uint32 returns the value of arg.
+func (arg Uint32BE) Uint32() uint32 {
+	return uint32(binary.BigEndian.Uint32(arg[:]))
+}
+
+func (arg Uint32BE) Format(w io.Writer) {
+	fmt.Fprintf(w, "%d", arg.Uint32())
+}
+
+func (arg Uint32BE) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Uint32BE) LoadObject(memory api.Memory, object []byte) Uint32BE {
+	return Uint32BE(object[:4])
+}
+
+func (arg Uint32BE) StoreObject(memory api.Memory, object []byte) {
+	copy(object, arg[:])
+}
+
+func (arg Uint32BE) ObjectSize() int {
+	return 4
+}
+
+var (
+	_ Object[Uint32BE] = Uint32BE{}
+	_ Formatter        = Uint32BE{}
+)
+
+// Uint64BE is an object type representing uint64 values stored in big-endian
+// byte order.
+type Uint64BE [8]byte
+
+// MakeUint64BE constructs a Uint64BE value from x.
+func MakeUint64BE(x uint64) (v Uint64BE) {
+	binary.BigEndian.PutUint64(v[:], x)
+	return v
+}
+
+// Uint64 returns the value of arg.
+func (arg Uint64BE) Uint64() uint64 {
+	return uint64(binary.BigEndian.Uint64(arg[:]))
+}
+
+func (arg Uint64BE) Format(w io.Writer) {
+	fmt.Fprintf(w, "%d", arg.Uint64())
+}
+
+func (arg Uint64BE) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Uint64BE) LoadObject(memory api.Memory, object []byte) Uint64BE {
+	return Uint64BE(object[:8])
+}
+
+func (arg Uint64BE) StoreObject(memory api.Memory, object []byte) {
+	copy(object, arg[:])
+}
+
+func (arg Uint64BE) ObjectSize() int {
+	return 8
+}
+
+var (
+	_ Object[Uint64BE] = Uint64BE{}
+	_ Formatter        = Uint64BE{}
+)
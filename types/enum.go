@@ -0,0 +1,237 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Symbol associates a name to a value of Enum or Flags types.
+type Symbol struct {
+	Value uint32
+	Name  string
+}
+
+// Symbols is an interface implemented by types declaring the table of symbolic
+// names of Enum and Flags types. The method is always called on the zero-value
+// of the type, which is why it is often implemented by empty struct types:
+//
+//	type whence struct{}
+//
+//	func (whence) Symbols() []types.Symbol {
+//		return []types.Symbol{
+//			{0, "SEEK_SET"},
+//			{1, "SEEK_CUR"},
+//			{2, "SEEK_END"},
+//		}
+//	}
+//
+//	type Whence = types.Enum[whence]
+//
+// If the type also has a method `Invalid() Errno`, the error code it returns is
+// used by the Validate methods of Enum and Flags to reject unknown values.
+type Symbols interface{ Symbols() []Symbol }
+
+func invalid[S Symbols](value string) error {
+	var s S
+	if i, ok := any(s).(interface{ Invalid() Errno }); ok {
+		return i.Invalid()
+	}
+	return fmt.Errorf("invalid value: %s", value)
+}
+
+// Enum is a type representing enumerations of 32 bits values, the symbolic
+// names of the values are declared by the type S.
+//
+// Enum values are formatted using their symbolic names, which makes logs more
+// readable than with raw integers (e.g. SEEK_END instead of 2).
+type Enum[S Symbols] uint32
+
+func (arg Enum[S]) Format(w io.Writer) {
+	if name, ok := arg.Name(); ok {
+		io.WriteString(w, name)
+	} else {
+		fmt.Fprintf(w, "%d", uint32(arg))
+	}
+}
+
+func (arg Enum[S]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	arg.LoadValue(memory, stack).Format(w)
+}
+
+func (arg Enum[S]) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Enum[S]) LoadValue(memory api.Memory, stack []uint64) Enum[S] {
+	return Enum[S](api.DecodeU32(stack[0]))
+}
+
+func (arg Enum[S]) LoadObject(memory api.Memory, object []byte) Enum[S] {
+	return Enum[S](binary.LittleEndian.Uint32(object))
+}
+
+func (arg Enum[S]) StoreValue(memory api.Memory, stack []uint64) {
+	stack[0] = api.EncodeU32(uint32(arg))
+}
+
+func (arg Enum[S]) StoreObject(memory api.Memory, object []byte) {
+	binary.LittleEndian.PutUint32(object, uint32(arg))
+}
+
+func (arg Enum[S]) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32}
+}
+
+func (arg Enum[S]) ObjectSize() int {
+	return 4
+}
+
+// Name returns the symbolic name of arg, and a boolean indicating whether the
+// value was found in the table of symbols.
+func (arg Enum[S]) Name() (string, bool) {
+	var s S
+	for _, sym := range s.Symbols() {
+		if sym.Value == uint32(arg) {
+			return sym.Name, true
+		}
+	}
+	return "", false
+}
+
+// Valid returns true if arg is one of the values declared in the table of
+// symbols.
+func (arg Enum[S]) Valid() bool {
+	_, ok := arg.Name()
+	return ok
+}
+
+// Validate returns nil if arg is valid, or an error otherwise. Host functions
+// can use it to reject unknown values:
+//
+//	if err := whence.Validate(); err != nil {
+//		return types.Fail(err)
+//	}
+func (arg Enum[S]) Validate() error {
+	if arg.Valid() {
+		return nil
+	}
+	return invalid[S](fmt.Sprintf("%d", uint32(arg)))
+}
+
+var (
+	_ Object[Enum[Symbols]] = Enum[Symbols](0)
+	_ Param[Enum[Symbols]]  = Enum[Symbols](0)
+	_ Result                = Enum[Symbols](0)
+	_ Formatter             = Enum[Symbols](0)
+)
+
+// Flags is a type representing sets of 32 bits flags, the symbolic names of the
+// flags are declared by the type S.
+//
+// Flags values are formatted as lists of symbolic names separated by "|" (e.g.
+// O_CREAT|O_RDWR). The symbols are matched in the order of the table, so flags
+// composed of multiple bits should be declared before the individual bits that
+// they are made of. Bits which do not match any symbols are written in
+// hexadecimal form.
+type Flags[S Symbols] uint32
+
+func (arg Flags[S]) Format(w io.Writer) {
+	var s S
+	var symbols = s.Symbols()
+	var bits = uint32(arg)
+
+	if bits == 0 {
+		for _, sym := range symbols {
+			if sym.Value == 0 {
+				io.WriteString(w, sym.Name)
+				return
+			}
+		}
+		io.WriteString(w, "0")
+		return
+	}
+
+	sep := ""
+	for _, sym := range symbols {
+		if sym.Value != 0 && (bits&sym.Value) == sym.Value {
+			io.WriteString(w, sep)
+			io.WriteString(w, sym.Name)
+			bits &^= sym.Value
+			sep = "|"
+		}
+	}
+	if bits != 0 {
+		fmt.Fprintf(w, "%s%#x", sep, bits)
+	}
+}
+
+func (arg Flags[S]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	arg.LoadValue(memory, stack).Format(w)
+}
+
+func (arg Flags[S]) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Flags[S]) LoadValue(memory api.Memory, stack []uint64) Flags[S] {
+	return Flags[S](api.DecodeU32(stack[0]))
+}
+
+func (arg Flags[S]) LoadObject(memory api.Memory, object []byte) Flags[S] {
+	return Flags[S](binary.LittleEndian.Uint32(object))
+}
+
+func (arg Flags[S]) StoreValue(memory api.Memory, stack []uint64) {
+	stack[0] = api.EncodeU32(uint32(arg))
+}
+
+func (arg Flags[S]) StoreObject(memory api.Memory, object []byte) {
+	binary.LittleEndian.PutUint32(object, uint32(arg))
+}
+
+func (arg Flags[S]) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32}
+}
+
+func (arg Flags[S]) ObjectSize() int {
+	return 4
+}
+
+// Has returns true if all the bits of flags are set in arg.
+func (arg Flags[S]) Has(flags Flags[S]) bool {
+	return (arg & flags) == flags
+}
+
+// Valid returns true if all the bits set in arg are declared in the table of
+// symbols.
+func (arg Flags[S]) Valid() bool {
+	var s S
+	var bits = uint32(arg)
+	for _, sym := range s.Symbols() {
+		bits &^= sym.Value
+	}
+	return bits == 0
+}
+
+// Validate returns nil if arg is valid, or an error otherwise. Host functions
+// can use it to reject unknown flags:
+//
+//	if err := oflags.Validate(); err != nil {
+//		return types.Fail(err)
+//	}
+func (arg Flags[S]) Validate() error {
+	if arg.Valid() {
+		return nil
+	}
+	return invalid[S](fmt.Sprintf("%#x", uint32(arg)))
+}
+
+var (
+	_ Object[Flags[Symbols]] = Flags[Symbols](0)
+	_ Param[Flags[Symbols]]  = Flags[Symbols](0)
+	_ Result                 = Flags[Symbols](0)
+	_ Formatter              = Flags[Symbols](0)
+)
@@ -0,0 +1,382 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Error codes of WASI preview 1.
+const (
+	ESUCCESS        Errno = 0  // No error occurred.
+	E2BIG           Errno = 1  // Argument list too long.
+	EACCES          Errno = 2  // Permission denied.
+	EADDRINUSE      Errno = 3  // Address in use.
+	EADDRNOTAVAIL   Errno = 4  // Address not available.
+	EAFNOSUPPORT    Errno = 5  // Address family not supported.
+	EAGAIN          Errno = 6  // Resource unavailable, or operation would block.
+	EALREADY        Errno = 7  // Connection already in progress.
+	EBADF           Errno = 8  // Bad file descriptor.
+	EBADMSG         Errno = 9  // Bad message.
+	EBUSY           Errno = 10 // Device or resource busy.
+	ECANCELED       Errno = 11 // Operation canceled.
+	ECHILD          Errno = 12 // No child processes.
+	ECONNABORTED    Errno = 13 // Connection aborted.
+	ECONNREFUSED    Errno = 14 // Connection refused.
+	ECONNRESET      Errno = 15 // Connection reset.
+	EDEADLK         Errno = 16 // Resource deadlock would occur.
+	EDESTADDRREQ    Errno = 17 // Destination address required.
+	EDOM            Errno = 18 // Mathematics argument out of domain of function.
+	EDQUOT          Errno = 19 // Reserved.
+	EEXIST          Errno = 20 // File exists.
+	EFAULT          Errno = 21 // Bad address.
+	EFBIG           Errno = 22 // File too large.
+	EHOSTUNREACH    Errno = 23 // Host is unreachable.
+	EIDRM           Errno = 24 // Identifier removed.
+	EILSEQ          Errno = 25 // Illegal byte sequence.
+	EINPROGRESS     Errno = 26 // Operation in progress.
+	EINTR           Errno = 27 // Interrupted function.
+	EINVAL          Errno = 28 // Invalid argument.
+	EIO             Errno = 29 // I/O error.
+	EISCONN         Errno = 30 // Socket is connected.
+	EISDIR          Errno = 31 // Is a directory.
+	ELOOP           Errno = 32 // Too many levels of symbolic links.
+	EMFILE          Errno = 33 // File descriptor value too large.
+	EMLINK          Errno = 34 // Too many links.
+	EMSGSIZE        Errno = 35 // Message too large.
+	EMULTIHOP       Errno = 36 // Reserved.
+	ENAMETOOLONG    Errno = 37 // Filename too long.
+	ENETDOWN        Errno = 38 // Network is down.
+	ENETRESET       Errno = 39 // Connection aborted by network.
+	ENETUNREACH     Errno = 40 // Network unreachable.
+	ENFILE          Errno = 41 // Too many files open in system.
+	ENOBUFS         Errno = 42 // No buffer space available.
+	ENODEV          Errno = 43 // No such device.
+	ENOENT          Errno = 44 // No such file or directory.
+	ENOEXEC         Errno = 45 // Executable file format error.
+	ENOLCK          Errno = 46 // No locks available.
+	ENOLINK         Errno = 47 // Reserved.
+	ENOMEM          Errno = 48 // Not enough space.
+	ENOMSG          Errno = 49 // No message of the desired type.
+	ENOPROTOOPT     Errno = 50 // Protocol not available.
+	ENOSPC          Errno = 51 // No space left on device.
+	ENOSYS          Errno = 52 // Function not supported.
+	ENOTCONN        Errno = 53 // The socket is not connected.
+	ENOTDIR         Errno = 54 // Not a directory or a symbolic link to a directory.
+	ENOTEMPTY       Errno = 55 // Directory not empty.
+	ENOTRECOVERABLE Errno = 56 // State not recoverable.
+	ENOTSOCK        Errno = 57 // Not a socket.
+	ENOTSUP         Errno = 58 // Not supported, or operation not supported on socket.
+	ENOTTY          Errno = 59 // Inappropriate I/O control operation.
+	ENXIO           Errno = 60 // No such device or address.
+	EOVERFLOW       Errno = 61 // Value too large to be stored in data type.
+	EOWNERDEAD      Errno = 62 // Previous owner died.
+	EPERM           Errno = 63 // Operation not permitted.
+	EPIPE           Errno = 64 // Broken pipe.
+	EPROTO          Errno = 65 // Protocol error.
+	EPROTONOSUPPORT Errno = 66 // Protocol not supported.
+	EPROTOTYPE      Errno = 67 // Protocol wrong type for socket.
+	ERANGE          Errno = 68 // Result too large.
+	EROFS           Errno = 69 // Read-only file system.
+	ESPIPE          Errno = 70 // Invalid seek.
+	ESRCH           Errno = 71 // No such process.
+	ESTALE          Errno = 72 // Reserved.
+	ETIMEDOUT       Errno = 73 // Connection timed out.
+	ETXTBSY         Errno = 74 // Text file busy.
+	EXDEV           Errno = 75 // Cross-device link.
+	ENOTCAPABLE     Errno = 76 // Capabilities insufficient.
+)
+
+// ErrorString is the symbolic name and human readable description of an error
+// code.
+type ErrorString struct {
+	Name string
+	Text string
+}
+
+// ErrorTable is a table of error strings indexed by error code.
+//
+// Host modules which use error codes of their own can declare a table and
+// expose it by implementing the ErrorTableOwner interface on their module
+// instances, which is used to format error codes in logs. The default table of
+// WASI preview 1 error codes is available as WASIErrors.
+type ErrorTable []ErrorString
+
+// Lookup returns the error string of errno, and a boolean indicating whether it
+// was found in the table.
+func (t ErrorTable) Lookup(errno Errno) (ErrorString, bool) {
+	if i := int(errno); i >= 0 && i < len(t) && t[i].Name != "" {
+		return t[i], true
+	}
+	return ErrorString{}, false
+}
+
+// Format writes a representation of errno to w, which contains both the name
+// and description of the error code (e.g. "EBADF (Bad file descriptor)"), or
+// the numeric value if it was not found in the table.
+func (t ErrorTable) Format(w io.Writer, errno Errno) {
+	if s, ok := t.Lookup(errno); !ok {
+		fmt.Fprintf(w, "errno(%d)", errno)
+	} else if s.Text == "" {
+		io.WriteString(w, s.Name)
+	} else {
+		fmt.Fprintf(w, "%s (%s)", s.Name, s.Text)
+	}
+}
+
+// ErrorTableOwner is an interface implemented by module instances declaring the
+// table of error strings of the error codes returned by their functions.
+//
+//	func (*Module) ErrorTable() types.ErrorTable {
+//		return types.WASIErrors
+//	}
+type ErrorTableOwner interface {
+	ErrorTable() ErrorTable
+}
+
+// ErrorFormatter is an interface implemented by values embedding error codes,
+// which can be formatted using a table of error strings.
+type ErrorFormatter interface {
+	FormatError(w io.Writer, memory api.Memory, stack []uint64, table ErrorTable)
+}
+
+// WASIErrors is the table of error strings of WASI preview 1 error codes.
+var WASIErrors = ErrorTable{
+	ESUCCESS:        {"ESUCCESS", "No error occurred"},
+	E2BIG:           {"E2BIG", "Argument list too long"},
+	EACCES:          {"EACCES", "Permission denied"},
+	EADDRINUSE:      {"EADDRINUSE", "Address in use"},
+	EADDRNOTAVAIL:   {"EADDRNOTAVAIL", "Address not available"},
+	EAFNOSUPPORT:    {"EAFNOSUPPORT", "Address family not supported"},
+	EAGAIN:          {"EAGAIN", "Resource unavailable, or operation would block"},
+	EALREADY:        {"EALREADY", "Connection already in progress"},
+	EBADF:           {"EBADF", "Bad file descriptor"},
+	EBADMSG:         {"EBADMSG", "Bad message"},
+	EBUSY:           {"EBUSY", "Device or resource busy"},
+	ECANCELED:       {"ECANCELED", "Operation canceled"},
+	ECHILD:          {"ECHILD", "No child processes"},
+	ECONNABORTED:    {"ECONNABORTED", "Connection aborted"},
+	ECONNREFUSED:    {"ECONNREFUSED", "Connection refused"},
+	ECONNRESET:      {"ECONNRESET", "Connection reset"},
+	EDEADLK:         {"EDEADLK", "Resource deadlock would occur"},
+	EDESTADDRREQ:    {"EDESTADDRREQ", "Destination address required"},
+	EDOM:            {"EDOM", "Mathematics argument out of domain of function"},
+	EDQUOT:          {"EDQUOT", "Reserved"},
+	EEXIST:          {"EEXIST", "File exists"},
+	EFAULT:          {"EFAULT", "Bad address"},
+	EFBIG:           {"EFBIG", "File too large"},
+	EHOSTUNREACH:    {"EHOSTUNREACH", "Host is unreachable"},
+	EIDRM:           {"EIDRM", "Identifier removed"},
+	EILSEQ:          {"EILSEQ", "Illegal byte sequence"},
+	EINPROGRESS:     {"EINPROGRESS", "Operation in progress"},
+	EINTR:           {"EINTR", "Interrupted function"},
+	EINVAL:          {"EINVAL", "Invalid argument"},
+	EIO:             {"EIO", "I/O error"},
+	EISCONN:         {"EISCONN", "Socket is connected"},
+	EISDIR:          {"EISDIR", "Is a directory"},
+	ELOOP:           {"ELOOP", "Too many levels of symbolic links"},
+	EMFILE:          {"EMFILE", "File descriptor value too large"},
+	EMLINK:          {"EMLINK", "Too many links"},
+	EMSGSIZE:        {"EMSGSIZE", "Message too large"},
+	EMULTIHOP:       {"EMULTIHOP", "Reserved"},
+	ENAMETOOLONG:    {"ENAMETOOLONG", "Filename too long"},
+	ENETDOWN:        {"ENETDOWN", "Network is down"},
+	ENETRESET:       {"ENETRESET", "Connection aborted by network"},
+	ENETUNREACH:     {"ENETUNREACH", "Network unreachable"},
+	ENFILE:          {"ENFILE", "Too many files open in system"},
+	ENOBUFS:         {"ENOBUFS", "No buffer space available"},
+	ENODEV:          {"ENODEV", "No such device"},
+	ENOENT:          {"ENOENT", "No such file or directory"},
+	ENOEXEC:         {"ENOEXEC", "Executable file format error"},
+	ENOLCK:          {"ENOLCK", "No locks available"},
+	ENOLINK:         {"ENOLINK", "Reserved"},
+	ENOMEM:          {"ENOMEM", "Not enough space"},
+	ENOMSG:          {"ENOMSG", "No message of the desired type"},
+	ENOPROTOOPT:     {"ENOPROTOOPT", "Protocol not available"},
+	ENOSPC:          {"ENOSPC", "No space left on device"},
+	ENOSYS:          {"ENOSYS", "Function not supported"},
+	ENOTCONN:        {"ENOTCONN", "The socket is not connected"},
+	ENOTDIR:         {"ENOTDIR", "Not a directory or a symbolic link to a directory"},
+	ENOTEMPTY:       {"ENOTEMPTY", "Directory not empty"},
+	ENOTRECOVERABLE: {"ENOTRECOVERABLE", "State not recoverable"},
+	ENOTSOCK:        {"ENOTSOCK", "Not a socket"},
+	ENOTSUP:         {"ENOTSUP", "Not supported, or operation not supported on socket"},
+	ENOTTY:          {"ENOTTY", "Inappropriate I/O control operation"},
+	ENXIO:           {"ENXIO", "No such device or address"},
+	EOVERFLOW:       {"EOVERFLOW", "Value too large to be stored in data type"},
+	EOWNERDEAD:      {"EOWNERDEAD", "Previous owner died"},
+	EPERM:           {"EPERM", "Operation not permitted"},
+	EPIPE:           {"EPIPE", "Broken pipe"},
+	EPROTO:          {"EPROTO", "Protocol error"},
+	EPROTONOSUPPORT: {"EPROTONOSUPPORT", "Protocol not supported"},
+	EPROTOTYPE:      {"EPROTOTYPE", "Protocol wrong type for socket"},
+	ERANGE:          {"ERANGE", "Result too large"},
+	EROFS:           {"EROFS", "Read-only file system"},
+	ESPIPE:          {"ESPIPE", "Invalid seek"},
+	ESRCH:           {"ESRCH", "No such process"},
+	ESTALE:          {"ESTALE", "Reserved"},
+	ETIMEDOUT:       {"ETIMEDOUT", "Connection timed out"},
+	ETXTBSY:         {"ETXTBSY", "Text file busy"},
+	EXDEV:           {"EXDEV", "Cross-device link"},
+	ENOTCAPABLE:     {"ENOTCAPABLE", "Capabilities insufficient"},
+}
+
+type errnoMapping struct {
+	err   error
+	errno Errno
+}
+
+// ErrnoRegistry is a set of mappings from Go errors to error codes, which is
+// used to convert Go errors that do not carry an error code. Errors are matched
+// using errors.Is, so the mappings also apply to errors wrapping them. Mappings
+// registered later take precedence over the previous ones.
+//
+// The zero-value is a valid empty registry. Registries are safe to use
+// concurrently from multiple goroutines.
+//
+// Host modules which need mappings of their own can declare a registry and
+// expose it by implementing the ErrnoRegistryOwner interface on their module
+// instances.
+type ErrnoRegistry struct {
+	mutex sync.RWMutex
+	table []errnoMapping
+}
+
+// Register installs a mapping from err to errno in the registry.
+func (r *ErrnoRegistry) Register(err error, errno Errno) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.table = append(r.table, errnoMapping{err, errno})
+}
+
+// Lookup returns the error code that err maps to, and a boolean indicating
+// whether a mapping was found in the registry.
+func (r *ErrnoRegistry) Lookup(err error) (Errno, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for i := len(r.table) - 1; i >= 0; i-- {
+		if m := r.table[i]; errors.Is(err, m.err) {
+			return m.errno, true
+		}
+	}
+	return 0, false
+}
+
+// ErrnoRegistryOwner is an interface implemented by module instances declaring
+// mappings from Go errors to the error codes returned by their functions.
+//
+//	type Module struct {
+//		errors types.ErrnoRegistry
+//	}
+//
+//	func (m *Module) ErrnoRegistry() *types.ErrnoRegistry {
+//		return &m.errors
+//	}
+//
+// The mappings of the module instance take precedence over the error codes
+// carried by the errors and the mappings installed by RegisterErrno. They are
+// applied when the host functions are created by one of the F* function
+// constructors of the wazergo package.
+type ErrnoRegistryOwner interface {
+	ErrnoRegistry() *ErrnoRegistry
+}
+
+// mappedError is an error that the registry of a module instance mapped to an
+// error code.
+type mappedError struct {
+	error
+	errno Errno
+}
+
+func (err *mappedError) Errno() int32  { return int32(err.errno) }
+func (err *mappedError) Unwrap() error { return err.error }
+
+// mapError applies the mappings of the module instance this to err, returning
+// an error carrying the error code if a mapping was found, or err otherwise.
+func mapError(this any, err error) error {
+	if err != nil {
+		if owner, ok := this.(ErrnoRegistryOwner); ok {
+			if errno, ok := owner.ErrnoRegistry().Lookup(err); ok {
+				return &mappedError{err, errno}
+			}
+		}
+	}
+	return err
+}
+
+// errnoRegistry is the registry of mappings installed by RegisterErrno.
+var errnoRegistry ErrnoRegistry
+
+func init() {
+	for _, m := range [...]errnoMapping{
+		{fs.ErrInvalid, EINVAL},
+		{fs.ErrPermission, EPERM},
+		{fs.ErrExist, EEXIST},
+		{fs.ErrNotExist, ENOENT},
+		{fs.ErrClosed, EBADF},
+		{os.ErrDeadlineExceeded, ETIMEDOUT},
+		{context.DeadlineExceeded, ETIMEDOUT},
+		{context.Canceled, ECANCELED},
+		{io.EOF, EIO},
+		{io.ErrUnexpectedEOF, EIO},
+		{io.ErrShortWrite, EIO},
+		{io.ErrShortBuffer, ENOBUFS},
+		{io.ErrClosedPipe, EPIPE},
+	} {
+		RegisterErrno(m.err, m.errno)
+	}
+}
+
+// RegisterErrno installs a mapping from err to errno in the registry shared by
+// all host modules, which AsErrno uses to convert Go errors that do not carry
+// an error code. Errors are matched using errors.Is, so the mapping also
+// applies to errors wrapping err.
+//
+// By default, the package installs mappings from the common errors of the Go
+// standard library (e.g. fs.ErrNotExist, context.DeadlineExceeded, io.EOF...)
+// to WASI preview 1 error codes. Mappings registered later take precedence over
+// the previous ones, which allows host modules to override the defaults. Host
+// modules usually register their mappings during initialization:
+//
+//	var ErrReadOnly = errors.New("read-only key")
+//
+//	func init() {
+//		types.RegisterErrno(ErrReadOnly, types.EPERM)
+//	}
+//
+// Since the registry is global, host modules which need mappings that do not
+// apply to other modules should declare their own ErrnoRegistry instead.
+//
+// The function is safe to call concurrently from multiple goroutines.
+func RegisterErrno(err error, errno Errno) {
+	errnoRegistry.Register(err, errno)
+}
+
+// syscallErrnos maps the error numbers of the host to WASI preview 1 error
+// codes, which differ on all platforms. The table is populated by the files of
+// the package which declare the error numbers available on each platform.
+var syscallErrnos = map[syscall.Errno]Errno{}
+
+type syscallErrno struct {
+	syscall syscall.Errno
+	errno   Errno
+}
+
+// registerSyscallErrnos adds the mappings to syscallErrnos. Some platforms give
+// several names to the same error number (e.g. EEXIST and ENOTEMPTY on AIX), in
+// which case the first mapping registered for the number is retained.
+func registerSyscallErrnos(mappings []syscallErrno) {
+	for _, m := range mappings {
+		if _, exists := syscallErrnos[m.syscall]; !exists {
+			syscallErrnos[m.syscall] = m.errno
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !plan9 && !openbsd
+
+package types
+
+import "syscall"
+
+// The error numbers of the STREAMS extensions are not defined on OpenBSD.
+func init() {
+	registerSyscallErrnos([]syscallErrno{
+		{syscall.EMULTIHOP, EMULTIHOP},
+		{syscall.ENOLINK, ENOLINK},
+	})
+}
@@ -0,0 +1,31 @@
+//go:build !plan9
+
+package types_test
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	. "github.com/stealthrocket/wazergo/types"
+)
+
+func TestAsErrnoSyscall(t *testing.T) {
+	tests := []struct {
+		err   error
+		errno Errno
+	}{
+		{syscall.EAGAIN, EAGAIN},
+		{syscall.ENOENT, ENOENT},
+		{&fs.PathError{Op: "open", Path: "/tmp/nope", Err: syscall.ENOENT}, ENOENT},
+		{&fs.PathError{Op: "read", Path: "/dev/tty", Err: syscall.EAGAIN}, EAGAIN},
+		{os.NewSyscallError("connect", syscall.ECONNREFUSED), ECONNREFUSED},
+		{fmt.Errorf("wrapped: %w", os.NewSyscallError("mkdir", syscall.EEXIST)), EEXIST},
+	}
+
+	for _, test := range tests {
+		assertEqual(t, AsErrno(test.err), test.errno)
+	}
+}
@@ -36,6 +36,66 @@ type Formatter interface {
 // program is better off providing a custom implementation of the method.
 func Format(w io.Writer, v any) { format(w, reflect.ValueOf(v)) }
 
+// FormatConfig configures optional behaviors of the formatting of values.
+//
+// The configuration applies to values formatted to writers returned by the
+// Writer method, for example:
+//
+//	config := types.FormatConfig{MaxDepth: 2, HexDump: true}
+//	value.FormatValue(config.Writer(w), memory, stack)
+type FormatConfig struct {
+	// MaxDepth is the maximum number of nested pointers that are followed when
+	// formatting the objects that pointers point to. Zero means that nested
+	// pointers are formatted as addresses, which is the default. Pointers
+	// creating cycles and pointers to addresses out of the bounds of memory
+	// are never followed.
+	MaxDepth int
+	// HexDump enables rendering byte buffers as hex dumps instead of quoted
+	// strings. The offsets displayed are the addresses of the bytes in memory
+	// when they are known, for example when formatting parameters.
+	HexDump bool
+	// MaxBytes is the maximum number of bytes of byte buffers which are
+	// displayed, the remaining bytes are elided. Zero means that the default
+	// limit applies: 20 bytes for quoted strings, and no limit for hex dumps.
+	MaxBytes int
+}
+
+// Writer returns a writer which applies the configuration to the values that
+// are formatted to it, and writes the output to w.
+func (c FormatConfig) Writer(w io.Writer) io.Writer {
+	return &formatWriter{Writer: w, config: c}
+}
+
+// formatWriter is the writer returned by FormatConfig.Writer, it carries the
+// configuration and the state of the formatting through calls to the Format*
+// methods.
+type formatWriter struct {
+	io.Writer
+	config FormatConfig
+	// pointers being followed, used to detect cycles
+	pointers []any
+}
+
+func formatWriterOf(w io.Writer) *formatWriter {
+	if fw, ok := w.(*formatWriter); ok {
+		return fw
+	}
+	return &formatWriter{Writer: w}
+}
+
+func (fw *formatWriter) depth() int {
+	return len(fw.pointers)
+}
+
+func (fw *formatWriter) visiting(ptr any) bool {
+	for _, p := range fw.pointers {
+		if p == ptr {
+			return true
+		}
+	}
+	return false
+}
+
 var (
 	formatterInterface = reflect.TypeOf((*Formatter)(nil)).Elem()
 	stringerInterface  = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
@@ -0,0 +1,277 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// HandleTable is a table of Go values exposed to guests as integer handles,
+// similarly to how file descriptors refer to kernel objects. Handles are
+// allocated from the lowest available number, handles of removed values are
+// reused.
+//
+// The zero-value is a valid empty table. Tables are safe to use concurrently
+// from multiple goroutines.
+//
+// Tables are intended to be owned by module instances, which expose them to
+// Handle parameters by implementing the HandleOwner interface:
+//
+//	type Module struct {
+//		files types.HandleTable[*os.File]
+//	}
+//
+//	func (m *Module) Handles() *types.HandleTable[*os.File] {
+//		return &m.files
+//	}
+//
+// Module instances created by the wazergo package close the tables exposed to
+// the parameters and results of their functions when they are closed, which
+// closes all the values that the tables contain (see HandleValue). Tables which
+// are not exposed this way must be declared by implementing HandleTableOwner.
+type HandleTable[V any] struct {
+	mutex  sync.Mutex
+	values []V
+	used   []bool
+	size   int
+	// Hint for the lowest handle that might be free, handles below this
+	// value are known to be used.
+	lowest int
+}
+
+// Insert adds v to the table and returns the handle that it was assigned.
+func (t *HandleTable[V]) Insert(v V) Handle[V] {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	i := t.lowest
+	for i < len(t.used) && t.used[i] {
+		i++
+	}
+	if i == len(t.used) {
+		t.values = append(t.values, v)
+		t.used = append(t.used, true)
+	} else {
+		t.values[i] = v
+		t.used[i] = true
+	}
+	t.lowest = i + 1
+	t.size++
+	return Handle[V]{handle: int32(i), value: v}
+}
+
+// Lookup returns the value associated with the handle h, and a boolean
+// indicating whether the handle was valid.
+func (t *HandleTable[V]) Lookup(h int32) (v V, ok bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.valid(h) {
+		v, ok = t.values[h], true
+	}
+	return
+}
+
+// Remove removes the handle h from the table, returning the value that was
+// associated with it, and a boolean indicating whether the handle was valid.
+//
+// The value is not closed, the caller is responsible for releasing resources
+// that it may hold.
+func (t *HandleTable[V]) Remove(h int32) (v V, ok bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.valid(h) {
+		var zero V
+		v, ok = t.values[h], true
+		t.values[h], t.used[h] = zero, false
+		if int(h) < t.lowest {
+			t.lowest = int(h)
+		}
+		t.size--
+	}
+	return
+}
+
+// Len returns the number of handles in the table.
+func (t *HandleTable[V]) Len() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.size
+}
+
+// Range calls fn for each handle of the table, in increasing order, until fn
+// returns false. The table must not be modified by fn.
+func (t *HandleTable[V]) Range(fn func(int32, V) bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for i, used := range t.used {
+		if used && !fn(int32(i), t.values[i]) {
+			break
+		}
+	}
+}
+
+// Close removes all the handles from the table, closing the values which
+// implement api.Closer or io.Closer. The method returns the errors returned by
+// the values it closed.
+func (t *HandleTable[V]) Close(ctx context.Context) error {
+	t.mutex.Lock()
+	values, used := t.values, t.used
+	t.values, t.used, t.size, t.lowest = nil, nil, 0, 0
+	t.mutex.Unlock()
+
+	var errs []error
+	for i, v := range values {
+		if !used[i] {
+			continue
+		}
+		var err error
+		switch c := any(v).(type) {
+		case api.Closer:
+			err = c.Close(ctx)
+		case io.Closer:
+			err = c.Close()
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *HandleTable[V]) valid(h int32) bool {
+	return h >= 0 && int(h) < len(t.used) && t.used[h]
+}
+
+// HandleOwner is an interface implemented by module instances owning a table of
+// handles to values of type V.
+type HandleOwner[V any] interface {
+	Handles() *HandleTable[V]
+}
+
+// HandleTableOwner is an interface implemented by module instances declaring
+// the tables of handles that they own, including tables which do not appear in
+// the signatures of their functions, for example because the handles are only
+// used internally and never passed to the guest.
+//
+// Module instances created by the wazergo package close the tables returned by
+// HandleTables when they are closed, in addition to the tables exposed to the
+// parameters and results of their functions.
+type HandleTableOwner interface {
+	HandleTables() []api.Closer
+}
+
+// HandleValue is an interface implemented by values resolving handles from the
+// tables of module instances, such as Handle or ExternRef, and by the types
+// wrapping them, such as Optional.
+//
+// The wazergo package uses this interface to find the tables of module
+// instances, which it closes automatically when the instances are closed.
+type HandleValue interface {
+	// Returns the table that the value resolves handles from on the module
+	// instance this, or nil if this does not own such a table.
+	HandleTable(this any) api.Closer
+}
+
+// HandleTableOf returns the table that v resolves handles from on the module
+// instance this, or nil if v does not hold handles or this does not own the
+// table.
+func HandleTableOf(this any, v Value) api.Closer {
+	if h, ok := v.(HandleValue); ok {
+		return h.HandleTable(this)
+	}
+	return nil
+}
+
+// Handle is a parameter and result type representing handles to Go values of
+// type V, held in the HandleTable of the module instance.
+//
+// When used as parameter, the handle is resolved to the Go value when it is
+// loaded. This requires the module instance to implement HandleOwner[V], and
+// the host function to be created by one of the F* function constructors.
+// Handles which do not exist in the table resolve to the error EBADF.
+//
+// Handle parameters are also resolved when they are wrapped in Optional or
+// ErrorFirst. They cannot be wrapped in Maybe since handles are not addresses,
+// guests usually represent missing handles with negative numbers, which always
+// resolve to EBADF.
+//
+// When used as result, the handle number is returned to the guest. Results are
+// usually obtained by inserting values in the table:
+//
+//	func (m *Module) Open(ctx context.Context, path String) Optional[Handle[*os.File]] {
+//		f, err := os.Open(string(path))
+//		if err != nil {
+//			return Err[Handle[*os.File]](err)
+//		}
+//		return Res(m.files.Insert(f))
+//	}
+type Handle[V any] struct {
+	handle int32
+	value  V
+	err    error
+}
+
+// Handle returns the handle number.
+func (h Handle[V]) Handle() int32 {
+	return h.handle
+}
+
+// Value returns the Go value that the handle was resolved to, or an error if
+// the handle was invalid.
+func (h Handle[V]) Value() (V, error) {
+	return h.value, h.err
+}
+
+func (h Handle[V]) Format(w io.Writer) {
+	fmt.Fprintf(w, "%d", h.handle)
+}
+
+func (h Handle[V]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	h.LoadValue(memory, stack).Format(w)
+}
+
+// LoadValue loads the handle number from the stack, without resolving it since
+// the handle table is not accessible. The Value method of the returned handle
+// always reports EBADF.
+func (h Handle[V]) LoadValue(memory api.Memory, stack []uint64) Handle[V] {
+	return Handle[V]{handle: api.DecodeI32(stack[0]), err: EBADF}
+}
+
+func (h Handle[V]) LoadContextValue(ctx context.Context, this any, module api.Module, stack []uint64) Handle[V] {
+	h = h.LoadValue(module.Memory(), stack)
+	if owner, ok := this.(HandleOwner[V]); ok {
+		if v, ok := owner.Handles().Lookup(h.handle); ok {
+			h.value, h.err = v, nil
+		}
+	}
+	return h
+}
+
+func (h Handle[V]) HandleTable(this any) api.Closer {
+	if owner, ok := this.(HandleOwner[V]); ok {
+		return owner.Handles()
+	}
+	return nil
+}
+
+func (h Handle[V]) StoreValue(memory api.Memory, stack []uint64) {
+	stack[0] = api.EncodeI32(h.handle)
+}
+
+func (h Handle[V]) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32}
+}
+
+var (
+	_ ContextParam[Handle[any]] = Handle[any]{}
+	_ Result                    = Handle[any]{}
+	_ Formatter                 = Handle[any]{}
+	_ HandleValue               = Handle[any]{}
+)
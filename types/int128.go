@@ -0,0 +1,169 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Int128 is an object type representing signed 128 bits integers, stored in
+// memory as 16 bytes in little-endian order.
+type Int128 struct {
+	Lo uint64
+	Hi int64
+}
+
+// BigInt returns the value of arg as a big integer.
+func (arg Int128) BigInt() *big.Int {
+	i := big.NewInt(arg.Hi)
+	i.Lsh(i, 64)
+	return i.Or(i, new(big.Int).SetUint64(arg.Lo))
+}
+
+func (arg Int128) Format(w io.Writer) {
+	io.WriteString(w, arg.BigInt().String())
+}
+
+func (arg Int128) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Int128) LoadObject(memory api.Memory, object []byte) Int128 {
+	return Int128{
+		Lo: binary.LittleEndian.Uint64(object[:8]),
+		Hi: int64(binary.LittleEndian.Uint64(object[8:])),
+	}
+}
+
+func (arg Int128) StoreObject(memory api.Memory, object []byte) {
+	binary.LittleEndian.PutUint64(object[:8], arg.Lo)
+	binary.LittleEndian.PutUint64(object[8:], uint64(arg.Hi))
+}
+
+func (arg Int128) ObjectSize() int {
+	return 16
+}
+
+var (
+	_ Object[Int128] = Int128{}
+	_ Formatter      = Int128{}
+)
+
+// Uint128 is an object type representing unsigned 128 bits integers, stored in
+// memory as 16 bytes in little-endian order. The type is commonly used for
+// values such as UUIDs, hashes, or large counters.
+type Uint128 struct {
+	Lo uint64
+	Hi uint64
+}
+
+// BigInt returns the value of arg as a big integer.
+func (arg Uint128) BigInt() *big.Int {
+	i := new(big.Int).SetUint64(arg.Hi)
+	i.Lsh(i, 64)
+	return i.Or(i, new(big.Int).SetUint64(arg.Lo))
+}
+
+func (arg Uint128) Format(w io.Writer) {
+	io.WriteString(w, arg.BigInt().String())
+}
+
+func (arg Uint128) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Uint128) LoadObject(memory api.Memory, object []byte) Uint128 {
+	return Uint128{
+		Lo: binary.LittleEndian.Uint64(object[:8]),
+		Hi: binary.LittleEndian.Uint64(object[8:]),
+	}
+}
+
+func (arg Uint128) StoreObject(memory api.Memory, object []byte) {
+	binary.LittleEndian.PutUint64(object[:8], arg.Lo)
+	binary.LittleEndian.PutUint64(object[8:], arg.Hi)
+}
+
+func (arg Uint128) ObjectSize() int {
+	return 16
+}
+
+var (
+	_ Object[Uint128] = Uint128{}
+	_ Formatter       = Uint128{}
+)
+
+// V128 is a parameter and result type representing values of the v128 type of
+// the WebAssembly SIMD extension. The vector is represented by its 16 bytes in
+// little-endian order, which allows access to its lanes using the functions
+// of the encoding/binary package:
+//
+//	x := binary.LittleEndian.Uint32(v[4:]) // i32x4 lane 1
+//
+// V128 values occupy two words on the stack, holding the low and high 64 bits
+// of the vector. V128 is also an object type which can be loaded from and
+// stored to memory.
+type V128 [16]byte
+
+// Lo returns the low 64 bits of arg.
+func (arg V128) Lo() uint64 {
+	return binary.LittleEndian.Uint64(arg[:8])
+}
+
+// Hi returns the high 64 bits of arg.
+func (arg V128) Hi() uint64 {
+	return binary.LittleEndian.Uint64(arg[8:])
+}
+
+// MakeV128 constructs a V128 value from its low and high 64 bits.
+func MakeV128(lo, hi uint64) (v V128) {
+	binary.LittleEndian.PutUint64(v[:8], lo)
+	binary.LittleEndian.PutUint64(v[8:], hi)
+	return v
+}
+
+func (arg V128) Format(w io.Writer) {
+	fmt.Fprintf(w, "0x%016x%016x", arg.Hi(), arg.Lo())
+}
+
+func (arg V128) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	arg.LoadValue(memory, stack).Format(w)
+}
+
+func (arg V128) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg V128) LoadValue(memory api.Memory, stack []uint64) V128 {
+	return MakeV128(stack[0], stack[1])
+}
+
+func (arg V128) LoadObject(memory api.Memory, object []byte) V128 {
+	return V128(object[:16])
+}
+
+func (arg V128) StoreValue(memory api.Memory, stack []uint64) {
+	stack[0], stack[1] = arg.Lo(), arg.Hi()
+}
+
+func (arg V128) StoreObject(memory api.Memory, object []byte) {
+	copy(object, arg[:])
+}
+
+func (arg V128) ValueTypes() []api.ValueType {
+	return []api.ValueType{ValueTypeV128}
+}
+
+func (arg V128) ObjectSize() int {
+	return 16
+}
+
+var (
+	_ Object[V128] = V128{}
+	_ Param[V128]  = V128{}
+	_ Result       = V128{}
+	_ Formatter    = V128{}
+)
@@ -0,0 +1,158 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/stealthrocket/wazergo/wasm"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// IOVecs is a parameter type representing scatter/gather lists of buffers, as
+// used by WASI functions such as fd_read or fd_write. On the stack, the list
+// is represented as a pair of pointer and number of entries, each entry being
+// a pair of 32 bits pointer and length in memory:
+//
+//	struct iovec {
+//		uint8_t *buf;
+//		uint32_t buf_len;
+//	};
+//
+// The buffers are views of the guest memory, no data is copied when loading
+// the parameter. The bounds of all the buffers are verified when the parameter
+// is loaded, which panics with a wasm.SEGFAULT if any of them is out of bounds.
+//
+// The layout of the entries is the one of 32 bits programs, IOVecs parameters
+// are therefore not supported in 64 bits addressing mode (memory64), where the
+// entries would be 16 bytes long.
+//
+// The Reader and Writer methods adapt the buffers to io.Reader and io.Writer
+// values. Since IOVecs values are slices of byte slices, they can also be
+// converted to net.Buffers to take advantage of vectored I/O.
+type IOVecs [][]byte
+
+// Len returns the total number of bytes in the buffers of arg.
+func (arg IOVecs) Len() (n int) {
+	for _, b := range arg {
+		n += len(b)
+	}
+	return n
+}
+
+// Reader returns a reader consuming the content of the buffers of arg.
+func (arg IOVecs) Reader() *IOVecReader {
+	return &IOVecReader{iovs: append(IOVecs(nil), arg...)}
+}
+
+// Writer returns a writer storing data to the buffers of arg.
+func (arg IOVecs) Writer() *IOVecWriter {
+	return &IOVecWriter{iovs: append(IOVecs(nil), arg...)}
+}
+
+func (arg IOVecs) Format(w io.Writer) {
+	fmt.Fprintf(w, "IOVecs(%d buffers, %d bytes)", len(arg), arg.Len())
+}
+
+func (arg IOVecs) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	arg.LoadValue(memory, stack).Format(w)
+}
+
+func (arg IOVecs) LoadValue(memory api.Memory, stack []uint64) IOVecs {
+	offset, count := stack[0], stack[1]
+	if count > math.MaxUint32 {
+		segfault(offset, mul64(count, 8))
+	}
+	entries := read(memory, offset, count*8)
+	iovs := make(IOVecs, count)
+	for i := range iovs {
+		entry := entries[i*8:]
+		ptr := binary.LittleEndian.Uint32(entry[:4])
+		length := binary.LittleEndian.Uint32(entry[4:])
+		iovs[i] = wasm.Read(memory, ptr, length)
+	}
+	return iovs
+}
+
+func (IOVecs) address() {}
+
+func (arg IOVecs) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
+}
+
+// ValueTypes64 returns nil since the entries hold 32 bits addresses, which
+// cannot be used in 64 bits addressing mode.
+func (arg IOVecs) ValueTypes64() []api.ValueType {
+	return nil
+}
+
+var (
+	_ Param[IOVecs] = IOVecs(nil)
+	_ Formatter     = IOVecs(nil)
+	_ Value64       = IOVecs(nil)
+)
+
+// IOVecReader is an io.Reader consuming the content of the buffers of an IOVecs
+// value.
+type IOVecReader struct {
+	iovs IOVecs
+	size int
+}
+
+// Read reads data from the buffers, following the data previously read.
+func (r *IOVecReader) Read(b []byte) (int, error) {
+	n := 0
+	for n < len(b) && len(r.iovs) > 0 {
+		c := copy(b[n:], r.iovs[0])
+		if r.iovs[0] = r.iovs[0][c:]; len(r.iovs[0]) == 0 {
+			r.iovs = r.iovs[1:]
+		}
+		n += c
+	}
+	r.size += n
+	if n == 0 && len(b) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// Len returns the number of bytes read.
+func (r *IOVecReader) Len() int {
+	return r.size
+}
+
+// IOVecWriter is an io.Writer storing data to the buffers of an IOVecs value.
+type IOVecWriter struct {
+	iovs IOVecs
+	size int
+}
+
+// Write stores data to the buffers, following the data previously written.
+// The method returns the number of bytes written, and io.ErrShortWrite if the
+// buffers did not have enough capacity to store all the data.
+func (w *IOVecWriter) Write(b []byte) (int, error) {
+	n := 0
+	for n < len(b) && len(w.iovs) > 0 {
+		c := copy(w.iovs[0], b[n:])
+		if w.iovs[0] = w.iovs[0][c:]; len(w.iovs[0]) == 0 {
+			w.iovs = w.iovs[1:]
+		}
+		n += c
+	}
+	w.size += n
+	if n < len(b) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+// Len returns the number of bytes written.
+func (w *IOVecWriter) Len() int {
+	return w.size
+}
+
+// Available returns the number of bytes that can still be written.
+func (w *IOVecWriter) Available() int {
+	return w.iovs.Len()
+}
@@ -0,0 +1,255 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf8"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// ErrorMessage is a result type returning an error code and a message
+// describing the error to the guest. The message is written to a buffer
+// received as parameter, and the function returns the error code and the full
+// length of the message, similarly to how snprintf reports the length of its
+// output.
+//
+// When the message does not fit in the buffer, it is truncated to the longest
+// prefix of complete UTF-8 sequences that fits. The bytes of the buffer which
+// follow the message are set to zero, so the guest can determine the length of
+// truncated messages by searching for the first zero byte, and can detect that
+// the message was truncated when the returned length is greater than the size
+// of its buffer.
+//
+//	func (m *Module) Put(ctx context.Context, key String, value Bytes, msg Bytes) ErrorMessage {
+//		return ErrMsg(msg, m.put(string(key), value))
+//	}
+type ErrorMessage struct {
+	buf Bytes
+	err error
+}
+
+// ErrMsg constructs an error message result from the buffer that the message
+// is written to and an error, which may be nil.
+func ErrMsg(buf Bytes, err error) ErrorMessage {
+	return ErrorMessage{buf: buf, err: err}
+}
+
+// Error returns the error embedded in msg.
+func (msg ErrorMessage) Error() error {
+	return msg.err
+}
+
+func (msg ErrorMessage) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	if err := makeErrno(api.DecodeI32(stack[0])); err != nil {
+		fmt.Fprintf(w, "ERROR: %v", err)
+		formatMessageLength(w, stack[1])
+	} else {
+		formatNone(w)
+	}
+}
+
+func (msg ErrorMessage) FormatError(w io.Writer, memory api.Memory, stack []uint64, table ErrorTable) {
+	if errno := Errno(api.DecodeI32(stack[0])); errno != 0 {
+		io.WriteString(w, "ERROR: ")
+		table.Format(w, errno)
+		formatMessageLength(w, stack[1])
+	} else {
+		formatNone(w)
+	}
+}
+
+func (msg ErrorMessage) StoreValue(memory api.Memory, stack []uint64) {
+	if msg.err == nil {
+		stack[0], stack[1] = 0, 0
+		return
+	}
+	s := msg.err.Error()
+	n := copy(msg.buf, truncateUTF8(s, len(msg.buf)))
+	for i := range msg.buf[n:] {
+		msg.buf[n+i] = 0
+	}
+	stack[0] = api.EncodeI32(int32(AsErrno(msg.err)))
+	stack[1] = uint64(len(s))
+}
+
+func (msg ErrorMessage) StoreContextValue(ctx context.Context, this any, module api.Module, stack []uint64) {
+	msg.err = mapError(this, msg.err)
+	msg.StoreValue(module.Memory(), stack)
+}
+
+// WithError returns an error message holding the error err, written to the
+// same buffer as msg.
+func (msg ErrorMessage) WithError(err error) Result {
+	return ErrMsg(msg.buf, err)
+}
+
+func (msg ErrorMessage) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
+}
+
+func (msg ErrorMessage) ValueTypes64() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI64}
+}
+
+var (
+	_ ContextResult  = ErrorMessage{}
+	_ ErrorResult    = ErrorMessage{}
+	_ ErrorFormatter = ErrorMessage{}
+	_ Value64        = ErrorMessage{}
+)
+
+// GuestAllocator is an interface implemented by types declaring the name of the
+// function exported by guest modules to allocate memory. The function must
+// have the signature (i32) -> i32, or (i64) -> i64 for programs using 64 bits
+// addressing, receiving the size of the allocation and returning the address
+// of the allocated memory.
+//
+// The method is always called on the zero-value of the type, which is why it is
+// often implemented by empty struct types. The Malloc type declares the common
+// "malloc" function.
+type GuestAllocator interface{ AllocFunction() string }
+
+// Malloc is a GuestAllocator for guests exporting the "malloc" function.
+type Malloc struct{}
+
+func (Malloc) AllocFunction() string { return "malloc" }
+
+// AllocErrorMessage is a result type returning an error code and a message
+// describing the error to the guest. The message is written to memory allocated
+// by calling the function exported by the guest declared by the type A, and the
+// function returns the error code, followed by the address and length of the
+// message. The guest is responsible for releasing the memory.
+//
+// No memory is allocated when there are no errors, in which case the address
+// and length are zero.
+//
+// Messages can only be allocated when the host functions are created by one of
+// the F* function constructors of the wazergo package. The host function panics
+// if the guest does not export the allocation function, or if the allocation
+// fails.
+//
+//	func (m *Module) Put(ctx context.Context, key String, value Bytes) AllocErrorMessage[Malloc] {
+//		return AllocErrMsg[Malloc](m.put(string(key), value))
+//	}
+type AllocErrorMessage[A GuestAllocator] struct {
+	err error
+}
+
+// AllocErrMsg constructs an error message result allocated in guest memory from
+// an error, which may be nil.
+func AllocErrMsg[A GuestAllocator](err error) AllocErrorMessage[A] {
+	return AllocErrorMessage[A]{err: err}
+}
+
+// Error returns the error embedded in msg.
+func (msg AllocErrorMessage[A]) Error() error {
+	return msg.err
+}
+
+func (msg AllocErrorMessage[A]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	if err := makeErrno(api.DecodeI32(stack[0])); err != nil {
+		fmt.Fprintf(w, "ERROR: %v", err)
+		formatMessage(w, memory, stack[1:])
+	} else {
+		formatNone(w)
+	}
+}
+
+func (msg AllocErrorMessage[A]) FormatError(w io.Writer, memory api.Memory, stack []uint64, table ErrorTable) {
+	if errno := Errno(api.DecodeI32(stack[0])); errno != 0 {
+		io.WriteString(w, "ERROR: ")
+		table.Format(w, errno)
+		formatMessage(w, memory, stack[1:])
+	} else {
+		formatNone(w)
+	}
+}
+
+// StoreValue stores the error code of msg to the stack. Since the guest memory
+// cannot be allocated, the address and length of the message are zero.
+func (msg AllocErrorMessage[A]) StoreValue(memory api.Memory, stack []uint64) {
+	stack[0] = api.EncodeI32(int32(AsErrno(msg.err)))
+	stack[1], stack[2] = 0, 0
+}
+
+func (msg AllocErrorMessage[A]) StoreContextValue(ctx context.Context, this any, module api.Module, stack []uint64) {
+	msg.err = mapError(this, msg.err)
+	msg.StoreValue(module.Memory(), stack)
+	if msg.err == nil {
+		return
+	}
+	s := msg.err.Error()
+	if s == "" {
+		return
+	}
+
+	var alloc A
+	name := alloc.AllocFunction()
+	fn := module.ExportedFunction(name)
+	if fn == nil {
+		panic(fmt.Errorf("%T: guest module does not export the %q function", msg, name))
+	}
+	def := fn.Definition()
+	params, results := def.ParamTypes(), def.ResultTypes()
+	if len(params) != 1 || len(results) != 1 || params[0] != results[0] ||
+		(params[0] != api.ValueTypeI32 && params[0] != api.ValueTypeI64) {
+		panic(fmt.Errorf("%T: the %q function of the guest module has an invalid signature: %v -> %v", msg, name, params, results))
+	}
+	ret, err := fn.Call(ctx, uint64(len(s)))
+	if err != nil {
+		panic(fmt.Errorf("%T: allocating %d bytes: %w", msg, len(s), err))
+	}
+	if ret[0] == 0 {
+		panic(fmt.Errorf("%T: allocating %d bytes: out of memory", msg, len(s)))
+	}
+
+	ptr := ret[0]
+	if ptr > math.MaxUint32 || !module.Memory().WriteString(uint32(ptr), s) {
+		panic(fmt.Errorf("%T: allocated memory is out of bounds (%d/%d)", msg, ptr, len(s)))
+	}
+	stack[1] = ptr
+	stack[2] = uint64(len(s))
+}
+
+func (msg AllocErrorMessage[A]) WithError(err error) Result {
+	return AllocErrMsg[A](err)
+}
+
+func (msg AllocErrorMessage[A]) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI32}
+}
+
+func (msg AllocErrorMessage[A]) ValueTypes64() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI64, api.ValueTypeI64}
+}
+
+var (
+	_ ContextResult  = AllocErrorMessage[Malloc]{}
+	_ ErrorResult    = AllocErrorMessage[Malloc]{}
+	_ ErrorFormatter = AllocErrorMessage[Malloc]{}
+	_ Value64        = AllocErrorMessage[Malloc]{}
+)
+
+func formatMessage(w io.Writer, memory api.Memory, stack []uint64) {
+	if n := stack[1]; n != 0 {
+		io.WriteString(w, ": ")
+		String("").FormatValue(w, memory, stack)
+	}
+}
+
+func formatMessageLength(w io.Writer, length uint64) {
+	fmt.Fprintf(w, " (message: %d bytes)", length)
+}
+
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
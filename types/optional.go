@@ -0,0 +1,307 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// ErrorFirst is a variant of Optional where the error code is returned to the
+// guest before the value, for guests which expect the error as first result.
+//
+// ErrorFirst values are obtained by converting Optional values, which allows
+// the Opt, Res, and Err functions to be used to construct them:
+//
+//	func (m *Module) Read(ctx context.Context, fd Int32, buf Bytes) ErrorFirst[Int32] {
+//		n, err := m.read(fd, buf)
+//		return ErrorFirst[Int32](Opt(Int32(n), err))
+//	}
+type ErrorFirst[T ParamResult[T]] Optional[T]
+
+// Result returns the underlying value of opt. The method panics if opt contained
+// an error.
+func (opt ErrorFirst[T]) Result() T {
+	return Optional[T](opt).Result()
+}
+
+// Error returns the error embedded in opt, or nil if opt contains a value.
+func (opt ErrorFirst[T]) Error() error {
+	return opt.err
+}
+
+func (opt ErrorFirst[T]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	if opt = opt.LoadValue(memory, stack); opt.err != nil {
+		fmt.Fprintf(w, "ERROR: %v", opt.err)
+	} else {
+		opt.res.FormatValue(w, memory, opt.values(stack))
+	}
+}
+
+func (opt ErrorFirst[T]) FormatError(w io.Writer, memory api.Memory, stack []uint64, table ErrorTable) {
+	if opt = opt.LoadValue(memory, stack); opt.err != nil {
+		io.WriteString(w, "ERROR: ")
+		table.Format(w, AsErrno(opt.err))
+	} else {
+		opt.res.FormatValue(w, memory, opt.values(stack))
+	}
+}
+
+func (opt ErrorFirst[T]) LoadValue(memory api.Memory, stack []uint64) ErrorFirst[T] {
+	opt.err = makeErrno(api.DecodeI32(stack[0]))
+	opt.res = opt.res.LoadValue(memory, opt.values(stack))
+	return opt
+}
+
+func (opt ErrorFirst[T]) LoadContextValue(ctx context.Context, this any, module api.Module, stack []uint64) ErrorFirst[T] {
+	opt.err = makeErrno(api.DecodeI32(stack[0]))
+	opt.res = loadContextValue[T](ctx, this, module, opt.values(stack))
+	return opt
+}
+
+func (opt ErrorFirst[T]) HandleTable(this any) api.Closer {
+	return HandleTableOf(this, opt.res)
+}
+
+func (opt ErrorFirst[T]) StoreValue(memory api.Memory, stack []uint64) {
+	values := opt.values(stack)
+	if opt.err != nil {
+		for i := range values {
+			values[i] = 0
+		}
+		stack[0] = api.EncodeI32(int32(AsErrno(opt.err)))
+	} else {
+		stack[0] = 0
+		opt.res.StoreValue(memory, values)
+	}
+}
+
+// values returns the section of the stack holding the value, which follows the
+// error code.
+func (opt ErrorFirst[T]) values(stack []uint64) []uint64 {
+	n := 1 + StackSize(opt.res.ValueTypes())
+	return stack[1:n:n]
+}
+
+func (opt ErrorFirst[T]) StoreContextValue(ctx context.Context, this any, module api.Module, stack []uint64) {
+	opt.err = mapError(this, opt.err)
+	opt.StoreValue(module.Memory(), stack)
+}
+
+func (opt ErrorFirst[T]) WithError(err error) Result {
+	return ErrorFirst[T](Err[T](err))
+}
+
+func (opt ErrorFirst[T]) ValueTypes() []api.ValueType {
+	return append([]api.ValueType{api.ValueTypeI32}, opt.res.ValueTypes()...)
+}
+
+func (opt ErrorFirst[T]) ValueTypes64() []api.ValueType {
+	if !Supports64(opt.res) {
+		return nil
+	}
+	return append([]api.ValueType{api.ValueTypeI32}, ValueTypes64(opt.res)...)
+}
+
+var (
+	_ ContextParam[ErrorFirst[None]] = ErrorFirst[None]{}
+	_ ContextResult                  = ErrorFirst[None]{}
+	_ ErrorResult                    = ErrorFirst[None]{}
+	_ ErrorFormatter                 = ErrorFirst[None]{}
+	_ Value64                        = ErrorFirst[None]{}
+	_ HandleValue                    = ErrorFirst[None]{}
+)
+
+// Integer is the constraint of integer types which can be combined with error
+// codes in a NegErrno result.
+type Integer[T any] interface {
+	ParamResult[T]
+	~int32 | ~int64
+}
+
+// NegErrno is a variant of Optional where errors are returned in the same slot
+// as the value, as the negated error code, similarly to how Linux system calls
+// report errors. The type can only be used with signed integer types, and the
+// values must not be negative; storing a negative value panics since the guest
+// would interpret it as an error code.
+//
+// NegErrno values are obtained by converting Optional values, which allows the
+// Opt, Res, and Err functions to be used to construct them:
+//
+//	func (m *Module) Write(ctx context.Context, fd Int32, buf Bytes) NegErrno[Int32] {
+//		n, err := m.write(fd, buf)
+//		return NegErrno[Int32](Opt(Int32(n), err))
+//	}
+type NegErrno[T Integer[T]] Optional[T]
+
+// Result returns the underlying value of opt. The method panics if opt contained
+// an error.
+func (opt NegErrno[T]) Result() T {
+	return Optional[T](opt).Result()
+}
+
+// Error returns the error embedded in opt, or nil if opt contains a value.
+func (opt NegErrno[T]) Error() error {
+	return opt.err
+}
+
+func (opt NegErrno[T]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	if opt = opt.LoadValue(memory, stack); opt.err != nil {
+		fmt.Fprintf(w, "ERROR: %v", opt.err)
+	} else {
+		opt.res.FormatValue(w, memory, stack)
+	}
+}
+
+func (opt NegErrno[T]) FormatError(w io.Writer, memory api.Memory, stack []uint64, table ErrorTable) {
+	if opt = opt.LoadValue(memory, stack); opt.err != nil {
+		io.WriteString(w, "ERROR: ")
+		table.Format(w, AsErrno(opt.err))
+	} else {
+		opt.res.FormatValue(w, memory, stack)
+	}
+}
+
+func (opt NegErrno[T]) LoadValue(memory api.Memory, stack []uint64) NegErrno[T] {
+	if res := opt.res.LoadValue(memory, stack); res < 0 {
+		opt.res, opt.err = 0, Errno(-res)
+	} else {
+		opt.res, opt.err = res, nil
+	}
+	return opt
+}
+
+func (opt NegErrno[T]) StoreValue(memory api.Memory, stack []uint64) {
+	if opt.err != nil {
+		errno := AsErrno(opt.err)
+		if errno > 0 {
+			errno = -errno
+		}
+		T(errno).StoreValue(memory, stack)
+	} else if opt.res < 0 {
+		panic(fmt.Errorf("%T: negative result cannot be distinguished from an error code: %d", opt, opt.res))
+	} else {
+		opt.res.StoreValue(memory, stack)
+	}
+}
+
+func (opt NegErrno[T]) StoreContextValue(ctx context.Context, this any, module api.Module, stack []uint64) {
+	opt.err = mapError(this, opt.err)
+	opt.StoreValue(module.Memory(), stack)
+}
+
+func (opt NegErrno[T]) WithError(err error) Result {
+	return NegErrno[T](Err[T](err))
+}
+
+func (opt NegErrno[T]) ValueTypes() []api.ValueType {
+	return opt.res.ValueTypes()
+}
+
+var (
+	_ Param[NegErrno[Int32]] = NegErrno[Int32]{}
+	_ ContextResult          = NegErrno[Int64]{}
+	_ ErrorResult            = NegErrno[Int64]{}
+	_ ErrorFormatter         = NegErrno[Int64]{}
+)
+
+// Output is a result type for functions writing their output to a pointer
+// received as parameter, and returning only the error code to the guest. The
+// value is written to memory only if there were no errors.
+//
+//	func (m *Module) Stat(ctx context.Context, path String, buf Pointer[Filestat]) Output[Filestat] {
+//		stat, err := m.stat(string(path))
+//		return Out(buf, stat, err)
+//	}
+type Output[T Object[T]] struct {
+	ptr Pointer[T]
+	res T
+	err error
+}
+
+// Out constructs an output value from the pointer that the result is written to
+// and a pair of result and error.
+func Out[T Object[T]](ptr Pointer[T], res T, err error) Output[T] {
+	return Output[T]{ptr: ptr, res: res, err: err}
+}
+
+// Result returns the value that is written to memory. The method panics if out
+// contained an error.
+func (out Output[T]) Result() T {
+	if out.err != nil {
+		panic(out.err)
+	}
+	return out.res
+}
+
+// Error returns the error embedded in out, or nil if out contains a value.
+func (out Output[T]) Error() error {
+	return out.err
+}
+
+func (out Output[T]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	if out = out.LoadValue(memory, stack); out.err != nil {
+		fmt.Fprintf(w, "ERROR: %v", out.err)
+	} else {
+		formatNone(w)
+	}
+}
+
+func (out Output[T]) FormatError(w io.Writer, memory api.Memory, stack []uint64, table ErrorTable) {
+	if errno := Errno(api.DecodeI32(stack[0])); errno != 0 {
+		io.WriteString(w, "ERROR: ")
+		table.Format(w, errno)
+	} else {
+		formatNone(w)
+	}
+}
+
+// LoadValue loads the error code from the stack. Since the pointer that the
+// value was written to is not known, the Result method of the returned output
+// returns the zero-value of T when there were no errors.
+func (out Output[T]) LoadValue(memory api.Memory, stack []uint64) Output[T] {
+	return Output[T]{err: makeErrno(api.DecodeI32(stack[0]))}
+}
+
+func (out Output[T]) StoreValue(memory api.Memory, stack []uint64) {
+	if out.err != nil {
+		stack[0] = api.EncodeI32(int32(AsErrno(out.err)))
+	} else {
+		out.ptr.Store(out.res)
+		stack[0] = 0
+	}
+}
+
+func (out Output[T]) StoreContextValue(ctx context.Context, this any, module api.Module, stack []uint64) {
+	out.err = mapError(this, out.err)
+	out.StoreValue(module.Memory(), stack)
+}
+
+// WithError returns an output holding the error err, which must not be nil since
+// the output does not have a pointer to write the value to.
+func (out Output[T]) WithError(err error) Result {
+	return Output[T]{err: err}
+}
+
+func (out Output[T]) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32}
+}
+
+// ValueTypes64 returns the same value types as ValueTypes since the error code
+// is a 32 bits integer in both addressing modes, unless T holds 32 bits
+// addresses which cannot be written to the memory of 64 bits programs.
+func (out Output[T]) ValueTypes64() []api.ValueType {
+	if layout32[T]() {
+		return nil
+	}
+	return out.ValueTypes()
+}
+
+var (
+	_ Param[Output[None]] = Output[None]{}
+	_ ContextResult       = Output[None]{}
+	_ ErrorResult         = Output[None]{}
+	_ ErrorFormatter      = Output[None]{}
+	_ Value64             = Output[None]{}
+)
@@ -0,0 +1,270 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// ExternRefOwner is an interface implemented by module instances owning a table
+// of Go values of type V exposed to guests as externref values.
+//
+// The table is distinct from the one used by Handle parameters, references and
+// handles do not share the same namespace even when they refer to values of the
+// same type.
+type ExternRefOwner[V any] interface {
+	ExternRefs() *HandleTable[V]
+}
+
+// ExternRef is a parameter and result type representing opaque references to
+// Go values of type V, passed to guests as externref values. Guests cannot
+// inspect or forge references, they can only store them and pass them back to
+// host functions.
+//
+// References are entries of the table returned by the ExternRefs method of the
+// module instance, which must implement ExternRefOwner[V], and the host
+// functions must be created by one of the F* function constructors. When used
+// as result, values constructed by Ref are inserted in the table. They remain
+// in the table until removed by the host module, or until the table is closed
+// with the module instance; since guests do not notify the host when they drop
+// references, host modules usually expose functions for guests to release the
+// references they do not need anymore:
+//
+//	func (m *Module) Connect(ctx context.Context, addr String) ExternRef[net.Conn] {
+//		c, err := net.Dial("tcp", string(addr))
+//		if err != nil {
+//			return ExternRef[net.Conn]{} // null reference
+//		}
+//		return Ref(c)
+//	}
+//
+//	func (m *Module) Release(ctx context.Context, ref ExternRef[net.Conn]) None {
+//		m.refs.Remove(ref.Handle())
+//		return None{}
+//	}
+//
+// The zero-value is the null reference, which guests can test for with the
+// ref.is_null instruction.
+type ExternRef[V any] struct {
+	ref   uint64 // handle + 1, zero means null or not inserted yet
+	value V
+	valid bool
+	err   error
+}
+
+// Ref constructs a reference to v, which is inserted in the table of the module
+// instance when returned to the guest.
+func Ref[V any](v V) ExternRef[V] {
+	return ExternRef[V]{value: v, valid: true}
+}
+
+// IsNil returns true if ref is the null reference.
+func (ref ExternRef[V]) IsNil() bool {
+	return ref.ref == 0 && !ref.valid
+}
+
+// Handle returns the handle of the value in the table of references, or -1 if
+// ref is not in the table.
+func (ref ExternRef[V]) Handle() int32 {
+	if ref.ref > math.MaxInt32+1 {
+		return -1
+	}
+	return int32(ref.ref - 1)
+}
+
+// Value returns the Go value that ref was resolved to, or an error if the
+// reference was null or invalid.
+func (ref ExternRef[V]) Value() (V, error) {
+	if ref.err == nil && !ref.valid {
+		return ref.value, EINVAL
+	}
+	return ref.value, ref.err
+}
+
+func (ref ExternRef[V]) Format(w io.Writer) {
+	switch {
+	case ref.ref != 0:
+		fmt.Fprintf(w, "externref(%d)", ref.Handle())
+	case ref.valid:
+		io.WriteString(w, "externref(?)")
+	default:
+		formatNull(w)
+	}
+}
+
+func (ref ExternRef[V]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	ref.LoadValue(memory, stack).Format(w)
+}
+
+// LoadValue loads the reference from the stack, without resolving it since the
+// table of references is not accessible. The Value method of the returned
+// reference always reports EBADF for non-null references.
+func (ref ExternRef[V]) LoadValue(memory api.Memory, stack []uint64) ExternRef[V] {
+	ref = ExternRef[V]{ref: stack[0]}
+	if ref.ref != 0 {
+		ref.err = EBADF
+	}
+	return ref
+}
+
+func (ref ExternRef[V]) LoadContextValue(ctx context.Context, this any, module api.Module, stack []uint64) ExternRef[V] {
+	ref = ref.LoadValue(module.Memory(), stack)
+	if ref.ref == 0 {
+		return ref
+	}
+	if owner, ok := this.(ExternRefOwner[V]); ok {
+		if v, ok := owner.ExternRefs().Lookup(ref.Handle()); ok {
+			ref.value, ref.valid, ref.err = v, true, nil
+		}
+	}
+	return ref
+}
+
+// StoreValue stores ref to the stack. Since the table of references is not
+// accessible, values constructed by Ref are stored as null references.
+func (ref ExternRef[V]) StoreValue(memory api.Memory, stack []uint64) {
+	stack[0] = ref.ref
+}
+
+func (ref ExternRef[V]) StoreContextValue(ctx context.Context, this any, module api.Module, stack []uint64) {
+	if ref.ref == 0 && ref.valid {
+		owner, ok := this.(ExternRefOwner[V])
+		if !ok {
+			panic(fmt.Errorf("%T: module instance of type %T does not own a table of references", ref, this))
+		}
+		ref.ref = uint64(owner.ExternRefs().Insert(ref.value).Handle()) + 1
+	}
+	ref.StoreValue(module.Memory(), stack)
+}
+
+func (ref ExternRef[V]) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeExternref}
+}
+
+func (ref ExternRef[V]) HandleTable(this any) api.Closer {
+	if owner, ok := this.(ExternRefOwner[V]); ok {
+		return owner.ExternRefs()
+	}
+	return nil
+}
+
+var (
+	_ ContextParam[ExternRef[any]] = ExternRef[any]{}
+	_ ContextResult                = ExternRef[any]{}
+	_ Formatter                    = ExternRef[any]{}
+	_ HandleValue                  = ExternRef[any]{}
+)
+
+// ExportRef is a parameter type representing references to functions exported
+// by the guest module, which host functions can call back into. References are
+// resolved by name, guests pass the name of one of their exported functions as
+// a pair of pointer and length, which is looked up in the exports of the guest
+// module when the parameter is loaded.
+//
+// ExportRef is not a funcref value, and does not give access to functions that
+// the guest only stores in its tables. The funcref value type (0x70) could be
+// declared in function signatures the same way as ValueTypeV128, but version
+// 1.1 of wazero passes funcref values to host functions as pointers to the
+// internal structures of its engines, and has no public API to call them or
+// to access the tables of modules, so they cannot be resolved to functions.
+//
+//	func (m *Module) Each(ctx context.Context, fn ExportRef) Error {
+//		f, err := fn.Function()
+//		if err != nil {
+//			return Fail(err)
+//		}
+//		for _, v := range m.values {
+//			if _, err := f.Call(ctx, v); err != nil {
+//				return Fail(err)
+//			}
+//		}
+//		return OK
+//	}
+//
+// An empty name represents a null reference. The function is only resolved
+// when the host function is created by one of the F* function constructors,
+// and references to functions that the guest does not export resolve to the
+// error ENOENT.
+type ExportRef struct {
+	name string
+	fn   api.Function
+	err  error
+}
+
+// IsNil returns true if ref is the null reference.
+func (ref ExportRef) IsNil() bool {
+	return ref.name == ""
+}
+
+// Name returns the name of the function that ref refers to.
+func (ref ExportRef) Name() string {
+	return ref.name
+}
+
+// Function returns the guest function that ref was resolved to, or an error if
+// the reference was null or invalid.
+func (ref ExportRef) Function() (api.Function, error) {
+	if ref.name == "" {
+		return nil, EINVAL
+	}
+	return ref.fn, ref.err
+}
+
+// Call calls the guest function that ref refers to.
+func (ref ExportRef) Call(ctx context.Context, params ...uint64) ([]uint64, error) {
+	fn, err := ref.Function()
+	if err != nil {
+		return nil, err
+	}
+	return fn.Call(ctx, params...)
+}
+
+func (ref ExportRef) Format(w io.Writer) {
+	if ref.name == "" {
+		formatNull(w)
+	} else {
+		fmt.Fprintf(w, "export(%s)", ref.name)
+	}
+}
+
+func (ref ExportRef) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	ref.LoadValue(memory, stack).Format(w)
+}
+
+// LoadValue loads the name of the function from memory, without resolving it
+// since the module is not accessible. The Function method of the returned
+// reference always reports ENOENT for non-null references.
+func (ref ExportRef) LoadValue(memory api.Memory, stack []uint64) ExportRef {
+	ref = ExportRef{name: string(read(memory, stack[0], stack[1]))}
+	if ref.name != "" {
+		ref.err = ENOENT
+	}
+	return ref
+}
+
+func (ref ExportRef) LoadContextValue(ctx context.Context, this any, module api.Module, stack []uint64) ExportRef {
+	ref = ref.LoadValue(module.Memory(), stack)
+	if ref.name != "" {
+		if fn := module.ExportedFunction(ref.name); fn != nil {
+			ref.fn, ref.err = fn, nil
+		}
+	}
+	return ref
+}
+
+func (ref ExportRef) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
+}
+
+func (ref ExportRef) ValueTypes64() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI64, api.ValueTypeI64}
+}
+
+var (
+	_ ContextParam[ExportRef] = ExportRef{}
+	_ Formatter               = ExportRef{}
+	_ Value64                 = ExportRef{}
+)
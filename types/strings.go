@@ -0,0 +1,184 @@
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/stealthrocket/wazergo/wasm"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Strings is a parameter type representing lists of strings. On the stack, the
+// list is represented as a pair of pointer and number of entries, each entry
+// being a pair of 32 bits pointer and length in memory:
+//
+//	struct string {
+//		const char *ptr;
+//		uint32_t len;
+//	};
+//
+// Like String, the values are copied to Go strings which do not share memory
+// with the WebAssembly program. The bounds of all the strings are verified
+// when the parameter is loaded, which panics with a wasm.SEGFAULT if any of
+// them is out of bounds.
+//
+// Since the entries hold 32 bits pointers, Strings parameters are not supported
+// in 64 bits addressing mode (memory64), where the entries would be 16 bytes
+// long.
+type Strings []string
+
+func (arg Strings) Format(w io.Writer) {
+	formatStrings(w, arg)
+}
+
+func (arg Strings) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	arg.LoadValue(memory, stack).Format(w)
+}
+
+func (arg Strings) LoadValue(memory api.Memory, stack []uint64) Strings {
+	offset, count := stack[0], stack[1]
+	if count > math.MaxUint32 {
+		segfault(offset, mul64(count, 8))
+	}
+	entries := read(memory, offset, count*8)
+	list := make(Strings, count)
+	for i := range list {
+		entry := entries[i*8:]
+		ptr := binary.LittleEndian.Uint32(entry[:4])
+		length := binary.LittleEndian.Uint32(entry[4:])
+		list[i] = string(wasm.Read(memory, ptr, length))
+	}
+	return list
+}
+
+func (Strings) address() {}
+
+func (arg Strings) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
+}
+
+// ValueTypes64 returns nil since the entries hold 32 bits addresses, which
+// cannot be used in 64 bits addressing mode.
+func (arg Strings) ValueTypes64() []api.ValueType {
+	return nil
+}
+
+var (
+	_ Param[Strings] = Strings(nil)
+	_ Formatter      = Strings(nil)
+	_ Value64        = Strings(nil)
+)
+
+// CStrings is a parameter type representing lists of strings packed in a
+// single buffer, each string being terminated by a NUL byte. This is the
+// layout used by WASI for the program arguments and environment variables.
+// On the stack, the list is represented as a pair of pointer and length of the
+// buffer; bytes following the last NUL byte are ignored.
+//
+// CStrings values also implement the host side of functions such as args_get
+// and environ_get: Sizes returns the values expected by args_sizes_get, and
+// Store writes the list to the memory of the guest:
+//
+//	func (m *Module) ArgsSizesGet(ctx context.Context, argc, size Pointer[Uint32]) Error {
+//		n, s := m.args.Sizes()
+//		argc.Store(Uint32(n))
+//		size.Store(Uint32(s))
+//		return OK
+//	}
+//
+//	func (m *Module) ArgsGet(ctx context.Context, argv, buf Pointer[Uint8]) Error {
+//		if err := m.args.Store(argv.Memory(), argv.Offset(), buf.Offset()); err != nil {
+//			return Fail(err)
+//		}
+//		return OK
+//	}
+type CStrings []string
+
+// Sizes returns the number of strings in arg and the size of the buffer needed
+// to store them, including the NUL bytes.
+func (arg CStrings) Sizes() (count, size int) {
+	for _, s := range arg {
+		size += len(s) + 1
+	}
+	return len(arg), size
+}
+
+// Store writes the strings of arg to the buffer at offset buf in memory, and
+// the array of pointers to each string at offset argv. The array must have
+// room for one 32 bits pointer per string, and the buffer room for the size
+// returned by Sizes. The method panics with a wasm.SEGFAULT if either of them
+// is out of bounds.
+//
+// Strings containing NUL bytes cannot be represented since the guest would see
+// them truncated, the method returns EINVAL without writing to memory if arg
+// contains any.
+func (arg CStrings) Store(memory api.Memory, argv, buf uint32) error {
+	for _, s := range arg {
+		if strings.IndexByte(s, 0) >= 0 {
+			return EINVAL
+		}
+	}
+	count, size := arg.Sizes()
+	ptrs := read(memory, uint64(argv), mul64(uint64(count), 4))
+	data := read(memory, uint64(buf), uint64(size))
+	offset := 0
+	for i, s := range arg {
+		binary.LittleEndian.PutUint32(ptrs[i*4:], buf+uint32(offset))
+		offset += copy(data[offset:], s)
+		data[offset] = 0
+		offset++
+	}
+	return nil
+}
+
+func (arg CStrings) Format(w io.Writer) {
+	formatStrings(w, arg)
+}
+
+func (arg CStrings) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	arg.LoadValue(memory, stack).Format(w)
+}
+
+func (arg CStrings) LoadValue(memory api.Memory, stack []uint64) CStrings {
+	data := read(memory, stack[0], stack[1])
+	list := make(CStrings, 0, bytes.Count(data, []byte{0}))
+	for {
+		i := bytes.IndexByte(data, 0)
+		if i < 0 {
+			return list
+		}
+		list = append(list, string(data[:i]))
+		data = data[i+1:]
+	}
+}
+
+func (CStrings) address() {}
+
+func (arg CStrings) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
+}
+
+func (arg CStrings) ValueTypes64() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI64, api.ValueTypeI64}
+}
+
+var (
+	_ Param[CStrings] = CStrings(nil)
+	_ Formatter       = CStrings(nil)
+	_ Value64         = CStrings(nil)
+)
+
+func formatStrings(w io.Writer, list []string) {
+	fmt.Fprintf(w, "[")
+	for i, s := range list {
+		if i > 0 {
+			fmt.Fprintf(w, ", ")
+		}
+		fmt.Fprintf(w, "%q", s)
+	}
+	fmt.Fprintf(w, "]")
+}
@@ -0,0 +1,162 @@
+package types
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// TimeUnit is an interface implemented by types declaring the unit of Timestamp
+// values. The method is always called on the zero-value of the type.
+//
+// The units must divide one second evenly, which is the case of the Seconds,
+// Milliseconds, Microseconds, and Nanoseconds types declared by this package.
+type TimeUnit interface{ Unit() time.Duration }
+
+// Seconds is a TimeUnit for timestamps expressed in seconds.
+type Seconds struct{}
+
+// Milliseconds is a TimeUnit for timestamps expressed in milliseconds.
+type Milliseconds struct{}
+
+// Microseconds is a TimeUnit for timestamps expressed in microseconds.
+type Microseconds struct{}
+
+// Nanoseconds is a TimeUnit for timestamps expressed in nanoseconds.
+type Nanoseconds struct{}
+
+func (Seconds) Unit() time.Duration      { return time.Second }
+func (Milliseconds) Unit() time.Duration { return time.Millisecond }
+func (Microseconds) Unit() time.Duration { return time.Microsecond }
+func (Nanoseconds) Unit() time.Duration  { return time.Nanosecond }
+
+// Timestamp is a type representing absolute points in time, expressed as a
+// number of units since the Unix epoch (e.g. Timestamp[Milliseconds] for the
+// number of milliseconds since January 1st 1970 UTC).
+//
+// Timestamps are represented as i64 values on the stack and 8 bytes integers
+// in memory. They are formatted in RFC 3339 format in the UTC time zone.
+type Timestamp[U TimeUnit] int64
+
+// MakeTimestamp converts t to a timestamp, truncating the time to the unit of
+// the timestamp.
+func MakeTimestamp[U TimeUnit](t time.Time) Timestamp[U] {
+	var u U
+	unit := u.Unit()
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+	return Timestamp[U](sec*int64(time.Second/unit) + nsec/int64(unit))
+}
+
+// Time converts arg to a time.Time value.
+func (arg Timestamp[U]) Time() time.Time {
+	var u U
+	unit := u.Unit()
+	per := int64(time.Second / unit)
+	sec, rem := int64(arg)/per, int64(arg)%per
+	return time.Unix(sec, rem*int64(unit))
+}
+
+func (arg Timestamp[U]) Format(w io.Writer) {
+	formatTime(w, arg.Time())
+}
+
+func (arg Timestamp[U]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	arg.LoadValue(memory, stack).Format(w)
+}
+
+func (arg Timestamp[U]) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Timestamp[U]) LoadValue(memory api.Memory, stack []uint64) Timestamp[U] {
+	return Timestamp[U](stack[0])
+}
+
+func (arg Timestamp[U]) LoadObject(memory api.Memory, object []byte) Timestamp[U] {
+	return Timestamp[U](binary.LittleEndian.Uint64(object))
+}
+
+func (arg Timestamp[U]) StoreValue(memory api.Memory, stack []uint64) {
+	stack[0] = uint64(arg)
+}
+
+func (arg Timestamp[U]) StoreObject(memory api.Memory, object []byte) {
+	binary.LittleEndian.PutUint64(object, uint64(arg))
+}
+
+func (arg Timestamp[U]) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI64}
+}
+
+func (arg Timestamp[U]) ObjectSize() int {
+	return 8
+}
+
+var (
+	_ Object[Timestamp[Seconds]] = Timestamp[Seconds](0)
+	_ Param[Timestamp[Seconds]]  = Timestamp[Seconds](0)
+	_ Result                     = Timestamp[Seconds](0)
+	_ Formatter                  = Timestamp[Seconds](0)
+)
+
+// Timespec is an object type representing the C struct timespec, which holds
+// a number of seconds and nanoseconds since the Unix epoch.
+//
+// The layout in memory is the one of 32 bits WebAssembly targets, where the
+// seconds are a 64 bits integer followed by the nanoseconds as a 32 bits
+// integer, and 4 bytes of padding:
+//
+//	struct timespec {
+//		int64_t tv_sec;
+//		long    tv_nsec;
+//	};
+type Timespec struct {
+	Sec  int64
+	Nsec int32
+}
+
+// MakeTimespec converts t to a Timespec value.
+func MakeTimespec(t time.Time) Timespec {
+	return Timespec{Sec: t.Unix(), Nsec: int32(t.Nanosecond())}
+}
+
+// Time converts arg to a time.Time value.
+func (arg Timespec) Time() time.Time {
+	return time.Unix(arg.Sec, int64(arg.Nsec))
+}
+
+func (arg Timespec) Format(w io.Writer) {
+	formatTime(w, arg.Time())
+}
+
+func (arg Timespec) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+func (arg Timespec) LoadObject(memory api.Memory, object []byte) Timespec {
+	return Timespec{
+		Sec:  int64(binary.LittleEndian.Uint64(object[:8])),
+		Nsec: int32(binary.LittleEndian.Uint32(object[8:])),
+	}
+}
+
+func (arg Timespec) StoreObject(memory api.Memory, object []byte) {
+	binary.LittleEndian.PutUint64(object[:8], uint64(arg.Sec))
+	binary.LittleEndian.PutUint32(object[8:], uint32(arg.Nsec))
+	binary.LittleEndian.PutUint32(object[12:], 0)
+}
+
+func (arg Timespec) ObjectSize() int {
+	return 16
+}
+
+var (
+	_ Object[Timespec] = Timespec{}
+	_ Formatter        = Timespec{}
+)
+
+func formatTime(w io.Writer, t time.Time) {
+	io.WriteString(w, t.UTC().Format(time.RFC3339Nano))
+}
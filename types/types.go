@@ -1,11 +1,12 @@
 package types
 
 import (
+	"context"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"io"
 	"math"
+	"math/bits"
 	"strconv"
 	"syscall"
 	"time"
@@ -24,12 +25,29 @@ type Value interface {
 	// Returns the sequence of primitive types that the result value is
 	// composed of. Values of primitive types will have a single type,
 	// but more complex types will have more (e.g. a buffer may hold two
-	// 32 bits integers for the pointer and length). The number of types
-	// indicates the number of words that are consumed from the stack when
-	// loading the parameter.
+	// 32 bits integers for the pointer and length). The number of words that
+	// are consumed from the stack when loading the parameter is given by
+	// StackSize.
 	ValueTypes() []api.ValueType
 }
 
+// ValueTypeV128 is the value type of 128 bits SIMD vectors.
+//
+// The value type is not exposed by the api package of wazero yet, but it is
+// supported in the signatures of host functions.
+const ValueTypeV128 api.ValueType = 0x7b
+
+// StackSize returns the number of words occupied on the stack by values of the
+// given types. Each value type uses one word, except v128 which uses two.
+func StackSize(valueTypes []api.ValueType) (size int) {
+	for _, t := range valueTypes {
+		if size++; t == ValueTypeV128 {
+			size++
+		}
+	}
+	return size
+}
+
 // Param is an interface representing parameters of WebAssembly functions which
 // are read form the stack.
 //
@@ -41,6 +59,34 @@ type Param[T any] interface {
 	LoadValue(memory api.Memory, stack []uint64) T
 }
 
+// ContextParam is an interface implemented by parameters which need access to
+// the context of the host function call to be loaded, for example to resolve
+// handles to Go values owned by the module instance.
+//
+// The F* function constructors of the wazergo package detect parameters which
+// implement this interface and call LoadContextValue instead of LoadValue.
+type ContextParam[T any] interface {
+	Param[T]
+	// Loads and returns the parameter value from the stack. The module instance
+	// that the host function is called on is passed as the this argument, and
+	// module is the guest module calling the host function.
+	LoadContextValue(ctx context.Context, this any, module api.Module, stack []uint64) T
+}
+
+// Validator is an interface implemented by parameters whose content must be
+// validated before being passed to host functions, for example UTF8 strings.
+//
+// The F* function constructors of the wazergo package detect parameters which
+// implement this interface and call ValidateValue before loading them. When a
+// parameter is rejected, the host function is not called and the error is
+// returned to the guest instead, which requires the result type to implement
+// ErrorResult. The call panics with the error if it does not.
+type Validator interface {
+	// Returns nil if the parameter value on the stack is valid, or an error
+	// describing why it was rejected.
+	ValidateValue(memory api.Memory, stack []uint64) error
+}
+
 // Result is an interface reprenting results of WebAssembly functions which
 // are written to the stack.
 //
@@ -52,6 +98,31 @@ type Result interface {
 	StoreValue(memory api.Memory, stack []uint64)
 }
 
+// ContextResult is an interface implemented by results which need access to the
+// context of the host function call to be stored, for example to allocate
+// memory by calling functions of the guest module.
+//
+// The F* function constructors of the wazergo package detect results which
+// implement this interface and call StoreContextValue instead of StoreValue.
+type ContextResult interface {
+	Result
+	// Stores the result value onto the stack. The module instance that the
+	// host function is called on is passed as the this argument, and module
+	// is the guest module calling the host function.
+	StoreContextValue(ctx context.Context, this any, module api.Module, stack []uint64)
+}
+
+// ErrorResult is an interface implemented by results which can represent
+// errors, such as Optional or ErrorMessage.
+//
+// The F* function constructors of the wazergo package use this interface to
+// return errors to the guest when parameters are rejected (see Validator).
+type ErrorResult interface {
+	Result
+	// Returns a result of the same type holding the error err.
+	WithError(err error) Result
+}
+
 // ParamResult is an interface implemented by types which can be used as both a
 // parameter and a result.
 type ParamResult[T any] interface {
@@ -110,6 +181,141 @@ func objectSize[T Object[T]]() int {
 	return typ.ObjectSize()
 }
 
+// Value64 is an interface implemented by values holding memory addresses or
+// lengths, which are represented by 64 bits integers instead of 32 bits when
+// the program uses 64 bits addressing (memory64).
+//
+// Values always decode memory addresses and lengths from the stack as 64 bits
+// integers, since wazero zero-extends 32 bits values the upper bits are always
+// zero in 32 bits addressing mode. Only the value types declared in function
+// signatures differ between the two modes.
+//
+// Values which cannot be used in 64 bits addressing mode, usually because they
+// load objects holding 32 bits addresses from memory (see Layout32), return nil
+// from ValueTypes64.
+type Value64 interface {
+	Value
+	// Returns the sequence of primitive types that the value is composed of
+	// when the program uses 64 bits addressing, or nil if the value is not
+	// supported in this mode.
+	ValueTypes64() []api.ValueType
+}
+
+// ValueTypes64 returns the sequence of primitive types that v is composed of in
+// 64 bits addressing mode. Values which do not implement Value64 are assumed to
+// have the same representation in both addressing modes.
+func ValueTypes64(v Value) []api.ValueType {
+	if v64, ok := v.(Value64); ok {
+		return v64.ValueTypes64()
+	}
+	return v.ValueTypes()
+}
+
+// Supports64 returns true if v can be used in 64 bits addressing mode.
+func Supports64(v Value) bool {
+	v64, ok := v.(Value64)
+	return !ok || v64.ValueTypes64() != nil
+}
+
+// Layout32 is an interface implemented by object types whose representation in
+// memory holds 32 bits addresses, for example Pointer or Bytes when they are
+// used as struct fields.
+//
+// The layout of objects in memory does not depend on the addressing mode of the
+// program, which means that these objects cannot be exchanged with programs
+// using 64 bits addressing (memory64). Values loading them from memory are not
+// supported in this mode, and are rejected by the Memory64 decorator of the
+// wazergo package. Struct types holding such fields should implement this
+// interface as well.
+type Layout32 interface {
+	// Returns true if the representation of the object in memory holds 32
+	// bits addresses.
+	Layout32() bool
+}
+
+func layout32[T Object[T]]() bool {
+	var typ T
+	l, ok := any(typ).(Layout32)
+	return ok && l.Layout32()
+}
+
+// loadContextValue loads a parameter of type P from the stack, calling its
+// LoadContextValue method if P implements ContextParam[P], or LoadValue if it
+// does not. Types wrapping parameters use it to propagate the context to the
+// values they contain.
+func loadContextValue[P Param[P]](ctx context.Context, this any, module api.Module, stack []uint64) P {
+	var arg P
+	if param, ok := any(arg).(ContextParam[P]); ok {
+		return param.LoadContextValue(ctx, this, module, stack)
+	}
+	return arg.LoadValue(module.Memory(), stack)
+}
+
+// read is like wasm.Read but accepts 64 bits offset and length, which trigger a
+// panic with a wasm.SEGFAULT64 error if they are beyond the 32 bits range of
+// wazero memories.
+func read(memory api.Memory, offset, length uint64) []byte {
+	if offset > math.MaxUint32 || length > math.MaxUint32 {
+		segfault(offset, length)
+	}
+	return wasm.Read(memory, uint32(offset), uint32(length))
+}
+
+// ParamRegions returns the regions of memory referenced by the parameters of a
+// host function, which are loaded from the stack and memory passed as arguments.
+// The returned regions are intended to be declared to a wasm.AuditMemory with
+// wasm.WithRegions, to verify that the host function does not access memory
+// beyond its parameters:
+//
+//	regions := types.ParamRegions(memory, fn.Params, stack)
+//	memory = wasm.NewAuditMemory(memory, wasm.WithRegions(regions...))
+//
+// The regions are found by recording the memory accesses made to format the
+// parameters, which follows pointers one level deep. Parameters which are out
+// of the bounds of memory contribute only the regions read before the fault.
+func ParamRegions(memory api.Memory, params []Value, stack []uint64) []wasm.Region {
+	audit := wasm.NewAuditMemory(memory)
+	for _, p := range params {
+		n := StackSize(p.ValueTypes())
+		v := stack[:n:n]
+		catchSegfault(func() { p.FormatValue(io.Discard, audit, v) })
+		stack = stack[n:]
+	}
+	return audit.Summary().Reads
+}
+
+// catchSegfault calls f and returns true, or false if f panicked with a
+// wasm.SEGFAULT or wasm.SEGFAULT64 error. Other panics are propagated.
+func catchSegfault(f func()) (ok bool) {
+	defer func() {
+		switch e := recover().(type) {
+		case nil, wasm.SEGFAULT, wasm.SEGFAULT64:
+		default:
+			panic(e)
+		}
+	}()
+	f()
+	return true
+}
+
+// segfault panics with a wasm.SEGFAULT error, or a wasm.SEGFAULT64 error if the
+// offset or length do not fit in 32 bits.
+func segfault(offset, length uint64) {
+	if offset > math.MaxUint32 || length > math.MaxUint32 {
+		panic(wasm.SEGFAULT64{Offset: offset, Length: length})
+	}
+	panic(wasm.SEGFAULT{Offset: uint32(offset), Length: uint32(length)})
+}
+
+// mul64 multiplies a and b, saturating on overflow.
+func mul64(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	if hi != 0 {
+		return math.MaxUint64
+	}
+	return lo
+}
+
 type Int8 int8
 
 func (arg Int8) Format(w io.Writer) {
@@ -694,18 +900,19 @@ func (arg Array[T]) FormatValue(w io.Writer, memory api.Memory, stack []uint64)
 func (arg Array[T]) LoadObject(memory api.Memory, object []byte) Array[T] {
 	offset := binary.LittleEndian.Uint32(object[:4])
 	length := binary.LittleEndian.Uint32(object[4:])
-	return arg.load(memory, offset, length)
+	return arg.load(memory, uint64(offset), uint64(length))
 }
 
 func (arg Array[T]) LoadValue(memory api.Memory, stack []uint64) Array[T] {
-	offset := api.DecodeU32(stack[0])
-	length := api.DecodeU32(stack[1])
-	return arg.load(memory, offset, length)
+	return arg.load(memory, stack[0], stack[1])
 }
 
-func (arg Array[T]) load(memory api.Memory, offset, length uint32) Array[T] {
-	size := unsafe.Sizeof(T(0))
-	data := wasm.Read(memory, offset, length*uint32(size))
+func (arg Array[T]) load(memory api.Memory, offset, length uint64) Array[T] {
+	size := uint64(unsafe.Sizeof(T(0)))
+	if length > math.MaxUint32 {
+		segfault(offset, mul64(length, size))
+	}
+	data := read(memory, offset, length*size)
 	return unsafe.Slice(*(**T)(unsafe.Pointer(&data)), length)
 }
 
@@ -723,8 +930,18 @@ func (arg Array[T]) ValueTypes() []api.ValueType {
 	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
 }
 
+func (arg Array[T]) ValueTypes64() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI64, api.ValueTypeI64}
+}
+
+func (arg Array[T]) Layout32() bool {
+	return true
+}
+
 var (
 	_ Param[Array[byte]] = Array[byte](nil)
+	_ Value64            = Array[byte](nil)
+	_ Layout32           = Array[byte](nil)
 	_ Formatter          = Array[byte](nil)
 )
 
@@ -733,23 +950,50 @@ var (
 type Bytes Array[byte]
 
 func (arg Bytes) Format(w io.Writer) {
-	if len(arg) <= 32 {
+	arg.format(w, 0)
+}
+
+// format writes arg to w. The offset is the address of arg in memory, which hex
+// dumps use to display the addresses of the bytes.
+func (arg Bytes) format(w io.Writer, offset uint32) {
+	config := formatWriterOf(w).config
+	if config.HexDump {
+		arg.formatHexDump(w, offset, config.MaxBytes)
+		return
+	}
+	limit, max := 20, 32
+	if config.MaxBytes > 0 {
+		limit, max = config.MaxBytes, config.MaxBytes
+	}
+	if len(arg) <= max {
 		fmt.Fprintf(w, "%q", arg)
 		return
 	}
-	b := arg[:20:20]
+	b := arg[:limit:limit]
 	b = append(b, "... ("...)
 	b = strconv.AppendUint(b, uint64(len(arg)), 10)
 	b = append(b, " bytes)"...)
 	fmt.Fprintf(w, "%q", b)
 }
 
+func (arg Bytes) formatHexDump(w io.Writer, offset uint32, limit int) {
+	b := arg
+	if limit > 0 && len(b) > limit {
+		b = b[:limit]
+	}
+	fmt.Fprintf(w, "(%d bytes)\n", len(arg))
+	wasm.HexDump(w, offset, b)
+	if len(b) < len(arg) {
+		fmt.Fprintf(w, "... (%d more bytes)\n", len(arg)-len(b))
+	}
+}
+
 func (arg Bytes) FormatObject(w io.Writer, memory api.Memory, object []byte) {
-	arg.LoadObject(memory, object).Format(w)
+	arg.LoadObject(memory, object).format(w, binary.LittleEndian.Uint32(object))
 }
 
 func (arg Bytes) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
-	arg.LoadValue(memory, stack).Format(w)
+	arg.LoadValue(memory, stack).format(w, uint32(stack[0]))
 }
 
 func (arg Bytes) LoadObject(memory api.Memory, object []byte) Bytes {
@@ -772,12 +1016,22 @@ func (arg Bytes) ValueTypes() []api.ValueType {
 	return arg.array().ValueTypes()
 }
 
+func (arg Bytes) ValueTypes64() []api.ValueType {
+	return arg.array().ValueTypes64()
+}
+
+func (arg Bytes) Layout32() bool {
+	return true
+}
+
 func (arg Bytes) array() Array[byte] {
 	return (Array[byte])(arg)
 }
 
 var (
 	_ Param[Bytes] = Bytes(nil)
+	_ Value64      = Bytes(nil)
+	_ Layout32     = Bytes(nil)
 	_ Formatter    = Bytes(nil)
 )
 
@@ -794,17 +1048,20 @@ func (arg String) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
 }
 
 func (arg String) LoadValue(memory api.Memory, stack []uint64) String {
-	offset := api.DecodeU32(stack[0])
-	length := api.DecodeU32(stack[1])
-	return String(wasm.Read(memory, offset, length))
+	return String(read(memory, stack[0], stack[1]))
 }
 
 func (arg String) ValueTypes() []api.ValueType {
 	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
 }
 
+func (arg String) ValueTypes64() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI64, api.ValueTypeI64}
+}
+
 var (
 	_ Param[String] = String("")
+	_ Value64       = String("")
 	_ Formatter     = String("")
 )
 
@@ -832,23 +1089,75 @@ func Ptr[T Object[T]](memory api.Memory, offset uint32) Pointer[T] {
 	return Pointer[T]{memory, offset}
 }
 
+// Format writes the object that arg points to, or the address of the object if
+// the maximum depth configured by FormatConfig was reached, the pointer creates
+// a cycle, or the address is out of the bounds of memory.
+func (arg Pointer[T]) Format(w io.Writer) {
+	fw := formatWriterOf(w)
+	arg.format(fw, fw.depth() <= fw.config.MaxDepth)
+}
+
 func (arg Pointer[T]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
-	arg = arg.LoadValue(memory, stack)
+	arg.LoadValue(memory, stack).format(formatWriterOf(w), true)
+}
+
+func (arg Pointer[T]) format(w *formatWriter, follow bool) {
+	if !follow {
+		fmt.Fprintf(w, "Pointer(%#x)", arg.offset)
+		return
+	}
+	// The memory is omitted from the key because api.Memory values are not
+	// guaranteed to be comparable.
+	key := Pointer[T]{offset: arg.offset}
+	if w.visiting(key) {
+		fmt.Fprintf(w, "Pointer(%#x) (cycle)", arg.offset)
+		return
+	}
+	// Objects holding addresses (e.g. Bytes) may also be out of bounds when
+	// they are loaded, which must not prevent formatting the rest of the
+	// values.
+	var value T
+	object, ok := arg.memory.Read(arg.offset, uint32(objectSize[T]()))
+	if ok {
+		ok = catchSegfault(func() { value = value.LoadObject(arg.memory, object) })
+	}
+	if !ok {
+		fmt.Fprintf(w, "Pointer(%#x) (out of bounds)", arg.offset)
+		return
+	}
+	w.pointers = append(w.pointers, key)
+	defer func() { w.pointers = w.pointers[:len(w.pointers)-1] }()
 	fmt.Fprintf(w, "&")
-	arg.Load().FormatObject(w, memory, arg.Object())
+	if !catchSegfault(func() { value.FormatObject(w, arg.memory, object) }) {
+		fmt.Fprintf(w, " (out of bounds)")
+	}
 }
 
 func (arg Pointer[T]) LoadValue(memory api.Memory, stack []uint64) Pointer[T] {
-	return Pointer[T]{memory, api.DecodeU32(stack[0])}
+	offset := stack[0]
+	if offset > math.MaxUint32 {
+		segfault(offset, uint64(objectSize[T]()))
+	}
+	return Pointer[T]{memory, uint32(offset)}
 }
 
 func (arg Pointer[T]) ValueTypes() []api.ValueType {
 	return []api.ValueType{api.ValueTypeI32}
 }
 
+func (arg Pointer[T]) ValueTypes64() []api.ValueType {
+	if layout32[T]() {
+		return nil
+	}
+	return []api.ValueType{api.ValueTypeI64}
+}
+
+func (arg Pointer[T]) Layout32() bool {
+	return true
+}
+
 func (arg Pointer[T]) FormatObject(w io.Writer, memory api.Memory, object []byte) {
-	// TODO: recursively follow pointer
-	fmt.Fprintf(w, "Pointer(%#x)", arg.offset)
+	arg.LoadObject(memory, object).Format(w)
 }
 
 func (arg Pointer[T]) LoadObject(memory api.Memory, object []byte) Pointer[T] {
@@ -909,10 +1218,155 @@ func (arg Pointer[T]) UnsafeSlice(count int) []T {
 	return unsafe.Slice((*T)(unsafe.Pointer(&data[0])), count)
 }
 
+// StoreSlice stores the values to the sequence of objects starting at the
+// pointer address. The whole memory area is bounds-checked before any of the
+// values are written, so a panic with a wasm.SEGFAULT error does not leave the
+// memory partially modified.
+func (arg Pointer[T]) StoreSlice(values []T) {
+	var typ T
+	size := typ.ObjectSize()
+	if len(values) == 0 || size == 0 {
+		return
+	}
+	data := wasm.Read(arg.memory, arg.offset, uint32(len(values)*size))
+	for i, v := range values {
+		v.StoreObject(arg.memory, data[i*size:(i+1)*size])
+	}
+}
+
+// UnsafeStoreSlice is the counterpart of UnsafeSlice, it copies the in-memory
+// representation of the values to the sequence of objects starting at the
+// pointer address. The method is only safe to use with types that have the
+// same layout in Go and WebAssembly memory (e.g. primitive types).
+func (arg Pointer[T]) UnsafeStoreSlice(values []T) {
+	var typ T
+	size := typ.ObjectSize()
+	if len(values) == 0 || size == 0 {
+		return
+	}
+	data := wasm.Read(arg.memory, arg.offset, uint32(len(values)*size))
+	copy(data, unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(values))), len(data)))
+}
+
 var (
 	_ Param[Pointer[None]] = Pointer[None]{}
+	_ Value64              = Pointer[None]{}
+	_ Layout32             = Pointer[None]{}
+	_ Formatter            = Pointer[None]{}
+)
+
+// Nullable is a pointer type similar to Pointer, but where the address zero
+// represents a NULL pointer instead of a valid memory location. Guests often
+// pass NULL pointers to indicate that an optional parameter was not provided.
+//
+// Methods of Nullable never dereference the address zero; attempting to load
+// or store a value through a NULL pointer panics with a wasm.SEGFAULT error.
+type Nullable[T Object[T]] struct {
+	ptr Pointer[T]
+}
+
+// NullPtr constructs a nullable pointer of objects T backed by a memory area at
+// a specified offset. The pointer is NULL if offset is zero.
+//
+// This function is mostly useful to construct pointers to pass to module
+// methods in tests, its usage in actual production code should be rare.
+func NullPtr[T Object[T]](memory api.Memory, offset uint32) Nullable[T] {
+	return Nullable[T]{Ptr[T](memory, offset)}
+}
+
+func (arg Nullable[T]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	if arg = arg.LoadValue(memory, stack); arg.IsNil() {
+		formatNull(w)
+	} else {
+		arg.ptr.FormatValue(w, memory, stack)
+	}
+}
+
+func (arg Nullable[T]) LoadValue(memory api.Memory, stack []uint64) Nullable[T] {
+	return Nullable[T]{arg.ptr.LoadValue(memory, stack)}
+}
+
+func (arg Nullable[T]) ValueTypes() []api.ValueType {
+	return arg.ptr.ValueTypes()
+}
+
+func (arg Nullable[T]) ValueTypes64() []api.ValueType {
+	return arg.ptr.ValueTypes64()
+}
+
+func (arg Nullable[T]) Layout32() bool {
+	return true
+}
+
+func (arg Nullable[T]) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	if arg = arg.LoadObject(memory, object); arg.IsNil() {
+		formatNull(w)
+	} else {
+		arg.ptr.FormatObject(w, memory, object)
+	}
+}
+
+func (arg Nullable[T]) LoadObject(memory api.Memory, object []byte) Nullable[T] {
+	return Nullable[T]{arg.ptr.LoadObject(memory, object)}
+}
+
+func (arg Nullable[T]) StoreObject(memory api.Memory, object []byte) {
+	arg.ptr.StoreObject(memory, object)
+}
+
+func (arg Nullable[T]) ObjectSize() int {
+	return arg.ptr.ObjectSize()
+}
+
+// IsNil returns true if arg is a NULL pointer.
+func (arg Nullable[T]) IsNil() bool {
+	return arg.ptr.offset == 0
+}
+
+// Pointer returns the non-NULL pointer held by arg, and a boolean which is true
+// if arg was not NULL.
+func (arg Nullable[T]) Pointer() (Pointer[T], bool) {
+	return arg.ptr, !arg.IsNil()
+}
+
+func (arg Nullable[T]) Memory() api.Memory {
+	return arg.ptr.Memory()
+}
+
+func (arg Nullable[T]) Offset() uint32 {
+	return arg.ptr.Offset()
+}
+
+func (arg Nullable[T]) Object() []byte {
+	arg.check()
+	return arg.ptr.Object()
+}
+
+func (arg Nullable[T]) Load() T {
+	arg.check()
+	return arg.ptr.Load()
+}
+
+func (arg Nullable[T]) Store(value T) {
+	arg.check()
+	arg.ptr.Store(value)
+}
+
+func (arg Nullable[T]) check() {
+	if arg.IsNil() {
+		segfault(0, uint64(objectSize[T]()))
+	}
+}
+
+var (
+	_ Object[Nullable[None]] = Nullable[None]{}
+	_ Param[Nullable[None]]  = Nullable[None]{}
+	_ Value64                = Nullable[None]{}
+	_ Layout32               = Nullable[None]{}
 )
 
+func formatNull(w io.Writer) { io.WriteString(w, "NULL") }
+
 // List represents a sequence of objects held in module memory.
 type List[T Object[T]] struct {
 	ptr Pointer[T]
@@ -940,9 +1394,12 @@ func (arg List[T]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
 }
 
 func (arg List[T]) LoadValue(memory api.Memory, stack []uint64) List[T] {
+	if length := stack[1]; length > math.MaxUint32 {
+		segfault(stack[0], mul64(length, uint64(objectSize[T]())))
+	}
 	return List[T]{
 		ptr: arg.ptr.LoadValue(memory, stack),
-		len: api.DecodeU32(stack[1]),
+		len: uint32(stack[1]),
 	}
 }
 
@@ -950,6 +1407,13 @@ func (arg List[T]) ValueTypes() []api.ValueType {
 	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
 }
 
+func (arg List[T]) ValueTypes64() []api.ValueType {
+	if layout32[T]() {
+		return nil
+	}
+	return []api.ValueType{api.ValueTypeI64, api.ValueTypeI64}
+}
+
 func (arg List[T]) Len() int {
 	return int(arg.len)
 }
@@ -981,8 +1445,165 @@ func (arg List[T]) UnsafeSlice() []T {
 	return arg.ptr.UnsafeSlice(arg.Len())
 }
 
+// StoreSlice stores the values to the list. The method panics if there are more
+// values than items in the list.
+func (arg List[T]) StoreSlice(values []T) {
+	arg.checkLen(len(values))
+	arg.ptr.StoreSlice(values)
+}
+
+// UnsafeStoreSlice is like StoreSlice but uses Pointer.UnsafeStoreSlice to copy
+// the values to memory.
+func (arg List[T]) UnsafeStoreSlice(values []T) {
+	arg.checkLen(len(values))
+	arg.ptr.UnsafeStoreSlice(values)
+}
+
+// CopyFrom stores values to the list, returning the number of items written,
+// which is the minimum of len(values) and arg.Len(), similarly to Go's copy
+// builtin.
+func (arg List[T]) CopyFrom(values []T) int {
+	if len(values) > arg.Len() {
+		values = values[:arg.Len()]
+	}
+	arg.ptr.StoreSlice(values)
+	return len(values)
+}
+
+// Writer returns a writer which can be used to sequentially store values to the
+// list.
+func (arg List[T]) Writer() *ListWriter[T] {
+	return &ListWriter[T]{list: arg}
+}
+
+func (arg List[T]) checkLen(n int) {
+	if n > arg.Len() {
+		panic(fmt.Errorf("%T: too many items (%d/%d)", arg, n, arg.Len()))
+	}
+}
+
 var (
 	_ Param[List[None]] = List[None]{}
+	_ Value64           = List[None]{}
+)
+
+// ListWriter is a typed writer storing sequences of values to a List.
+type ListWriter[T Object[T]] struct {
+	list List[T]
+	size int
+}
+
+// Write stores values to the list, following the values previously written.
+// The method returns the number of values written, and io.ErrShortWrite if the
+// list did not have enough capacity to store all the values.
+func (w *ListWriter[T]) Write(values ...T) (int, error) {
+	list := List[T]{
+		ptr: w.list.ptr.Index(w.size),
+		len: uint32(w.Available()),
+	}
+	n := list.CopyFrom(values)
+	w.size += n
+	if n < len(values) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+// Len returns the number of values written to the list.
+func (w *ListWriter[T]) Len() int {
+	return w.size
+}
+
+// Available returns the number of values that can still be written.
+func (w *ListWriter[T]) Available() int {
+	return w.list.Len() - w.size
+}
+
+// Length is an interface used to declare the number of items of FixedArray
+// types. The length is a property of the type, the method is always called on
+// the zero-value, which is why it is often implemented by empty struct types:
+//
+//	type six struct{}
+//
+//	func (six) Len() int { return 6 }
+//
+//	type MAC = types.FixedArray[types.Uint8, six]
+type Length interface{ Len() int }
+
+// FixedArray is an object type representing a sequence of a fixed number of
+// objects held inline in memory, similar to C arrays such as uint32_t regs[16].
+// The number of items is declared by the type N.
+//
+// Unlike Array and List which are pairs of pointer and length, FixedArray
+// values are laid out in the memory area of the object that contains them,
+// which makes them usable as fields of struct types, or as targets of Pointer
+// and List types.
+type FixedArray[T Object[T], N Length] []T
+
+func (arg FixedArray[T, N]) Format(w io.Writer) {
+	object := make([]byte, arg.ObjectSize())
+	arg.StoreObject(nil, object)
+	arg.FormatObject(w, nil, object)
+}
+
+func (arg FixedArray[T, N]) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	var typ T
+	size := typ.ObjectSize()
+	fmt.Fprintf(w, "[")
+	for i := 0; i < arg.Len(); i++ {
+		if i > 0 {
+			fmt.Fprintf(w, ",")
+		}
+		typ.FormatObject(w, memory, object[i*size:(i+1)*size])
+	}
+	fmt.Fprintf(w, "]")
+}
+
+func (arg FixedArray[T, N]) LoadObject(memory api.Memory, object []byte) FixedArray[T, N] {
+	var typ T
+	size := typ.ObjectSize()
+	values := make(FixedArray[T, N], arg.Len())
+	for i := range values {
+		values[i] = typ.LoadObject(memory, object[i*size:(i+1)*size])
+	}
+	return values
+}
+
+func (arg FixedArray[T, N]) StoreObject(memory api.Memory, object []byte) {
+	if len(arg) > arg.Len() {
+		panic(fmt.Errorf("%T: too many items (%d/%d)", arg, len(arg), arg.Len()))
+	}
+	var typ T
+	size := typ.ObjectSize()
+	for i, v := range arg {
+		v.StoreObject(memory, object[i*size:(i+1)*size])
+	}
+	// Missing items are zero-initialized, which matches the behavior of array
+	// initializers in C and Go.
+	tail := object[len(arg)*size : arg.ObjectSize()]
+	for i := range tail {
+		tail[i] = 0
+	}
+}
+
+func (arg FixedArray[T, N]) ObjectSize() int {
+	return arg.Len() * objectSize[T]()
+}
+
+func (arg FixedArray[T, N]) Layout32() bool {
+	return layout32[T]()
+}
+
+// Len returns the number of items in the array, as declared by the type N.
+func (arg FixedArray[T, N]) Len() int {
+	var n N
+	return n.Len()
+}
+
+var (
+	_ Object[FixedArray[None, Length]] = FixedArray[None, Length](nil)
+	_ Layout32                         = FixedArray[None, Length](nil)
+	_ Formatter                        = FixedArray[None, Length](nil)
 )
 
 // Optional represents a function result which may be missing due to the program
@@ -1007,22 +1628,42 @@ func (opt Optional[T]) Error() error {
 }
 
 func (opt Optional[T]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
-	if opt.err != nil {
+	if opt = opt.LoadValue(memory, stack); opt.err != nil {
 		fmt.Fprintf(w, "ERROR: %v", opt.err)
 	} else {
 		opt.res.FormatValue(w, memory, stack)
 	}
 }
 
+func (opt Optional[T]) FormatError(w io.Writer, memory api.Memory, stack []uint64, table ErrorTable) {
+	if opt = opt.LoadValue(memory, stack); opt.err != nil {
+		io.WriteString(w, "ERROR: ")
+		table.Format(w, AsErrno(opt.err))
+	} else {
+		opt.res.FormatValue(w, memory, stack)
+	}
+}
+
 func (opt Optional[T]) LoadValue(memory api.Memory, stack []uint64) Optional[T] {
-	n := len(opt.res.ValueTypes())
+	n := StackSize(opt.res.ValueTypes())
 	opt.res = opt.res.LoadValue(memory, stack[:n:n])
 	opt.err = makeErrno(api.DecodeI32(stack[n]))
 	return opt
 }
 
+func (opt Optional[T]) LoadContextValue(ctx context.Context, this any, module api.Module, stack []uint64) Optional[T] {
+	n := StackSize(opt.res.ValueTypes())
+	opt.res = loadContextValue[T](ctx, this, module, stack[:n:n])
+	opt.err = makeErrno(api.DecodeI32(stack[n]))
+	return opt
+}
+
+func (opt Optional[T]) HandleTable(this any) api.Closer {
+	return HandleTableOf(this, opt.res)
+}
+
 func (opt Optional[T]) StoreValue(memory api.Memory, stack []uint64) {
-	if n := len(opt.res.ValueTypes()); opt.err != nil {
+	if n := StackSize(opt.res.ValueTypes()); opt.err != nil {
 		for i := range stack[:n] {
 			stack[i] = 0
 		}
@@ -1033,10 +1674,26 @@ func (opt Optional[T]) StoreValue(memory api.Memory, stack []uint64) {
 	}
 }
 
+func (opt Optional[T]) StoreContextValue(ctx context.Context, this any, module api.Module, stack []uint64) {
+	opt.err = mapError(this, opt.err)
+	opt.StoreValue(module.Memory(), stack)
+}
+
+func (opt Optional[T]) WithError(err error) Result {
+	return Err[T](err)
+}
+
 func (opt Optional[T]) ValueTypes() []api.ValueType {
 	return append(opt.res.ValueTypes(), api.ValueTypeI32)
 }
 
+func (opt Optional[T]) ValueTypes64() []api.ValueType {
+	if !Supports64(opt.res) {
+		return nil
+	}
+	return append(ValueTypes64(opt.res), api.ValueTypeI32)
+}
+
 // Opt constructs an optional from a pair of a result and error.
 func Opt[T ParamResult[T]](res T, err error) Optional[T] {
 	return Optional[T]{res: res, err: err}
@@ -1057,8 +1714,93 @@ func Err[T ParamResult[T]](err error) Optional[T] {
 }
 
 var (
-	_ Param[Optional[None]] = Optional[None]{}
-	_ Result                = Optional[None]{}
+	_ ErrorFormatter               = Optional[None]{}
+	_ ContextParam[Optional[None]] = Optional[None]{}
+	_ ContextResult                = Optional[None]{}
+	_ ErrorResult                  = Optional[None]{}
+	_ Value64                      = Optional[None]{}
+	_ HandleValue                  = Optional[None]{}
+)
+
+// Address is a type constraint matching the parameter types whose first stack
+// value is a memory address, such as Bytes, String, Pointer, Nullable or List.
+// Guests represent the absence of those parameters by passing NULL (zero).
+type Address[T any] interface {
+	Param[T]
+	address()
+}
+
+func (Array[T]) address()    {}
+func (Bytes) address()       {}
+func (String) address()      {}
+func (Pointer[T]) address()  {}
+func (Nullable[T]) address() {}
+func (List[T]) address()     {}
+
+// Maybe is a parameter type wrapping values of type T which the guest may omit
+// by passing NULL. The parameter is absent when the address held in its first
+// stack value is zero.
+//
+// All the bits of the address are tested: in 64 bits addressing mode, addresses
+// beyond the range of memory are not mistaken for NULL, and loading the value
+// panics with a wasm.SEGFAULT64 error.
+//
+// When the parameter is absent, the value of type T is not loaded, which means
+// that the address zero is never dereferenced.
+type Maybe[T Address[T]] struct {
+	value T
+	valid bool
+}
+
+// Just constructs a Maybe value holding v.
+func Just[T Address[T]](v T) Maybe[T] {
+	return Maybe[T]{value: v, valid: true}
+}
+
+// Get returns the underlying value, and a boolean indicating whether the value
+// was present.
+func (arg Maybe[T]) Get() (T, bool) {
+	return arg.value, arg.valid
+}
+
+// IsNil returns true if the value was absent.
+func (arg Maybe[T]) IsNil() bool {
+	return !arg.valid
+}
+
+func (arg Maybe[T]) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	if isNull(stack) {
+		formatNull(w)
+	} else {
+		arg.value.FormatValue(w, memory, stack)
+	}
+}
+
+func (arg Maybe[T]) LoadValue(memory api.Memory, stack []uint64) Maybe[T] {
+	if isNull(stack) {
+		return Maybe[T]{}
+	}
+	return Just(arg.value.LoadValue(memory, stack))
+}
+
+func (arg Maybe[T]) ValueTypes() []api.ValueType {
+	return arg.value.ValueTypes()
+}
+
+func (arg Maybe[T]) ValueTypes64() []api.ValueType {
+	return ValueTypes64(arg.value)
+}
+
+// isNull returns true if the address on the stack is zero. All the bits of the
+// value are tested so 64 bits addresses which are out of the 32 bits range are
+// not mistaken for NULL, and fault when they are loaded.
+func isNull(stack []uint64) bool {
+	return stack[0] == 0
+}
+
+var (
+	_ Param[Maybe[Bytes]] = Maybe[Bytes]{}
+	_ Value64             = Maybe[Bytes]{}
 )
 
 // None is a special type of size zero bytes.
@@ -1135,6 +1877,10 @@ func (err Errno) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
 	io.WriteString(w, err.LoadValue(memory, stack).Error())
 }
 
+func (err Errno) FormatError(w io.Writer, memory api.Memory, stack []uint64, table ErrorTable) {
+	table.Format(w, err.LoadValue(memory, stack))
+}
+
 func (err Errno) LoadValue(memory api.Memory, stack []uint64) Errno {
 	return Errno(api.DecodeI32(stack[0]))
 }
@@ -1148,8 +1894,9 @@ func (err Errno) ValueTypes() []api.ValueType {
 }
 
 var (
-	_ Param[Errno] = Errno(0)
-	_ Result       = Errno(0)
+	_ Param[Errno]   = Errno(0)
+	_ Result         = Errno(0)
+	_ ErrorFormatter = Errno(0)
 
 	// ErrorStrings is a global used in the formatting of Errno values.
 	//
@@ -1158,6 +1905,10 @@ var (
 	//
 	// There is no synchronization so it is recommended to assign this global
 	// during program initialization (e.g. in an init function).
+	//
+	// Since the global is shared by all host modules of the program, host
+	// modules should prefer declaring their error strings in an ErrorTable
+	// which applies only to the functions of the module, see ErrorTableOwner.
 	ErrorStrings []string
 )
 
@@ -1168,20 +1919,45 @@ func makeErrno(errno int32) error {
 	return Errno(errno)
 }
 
+// AsErrno converts a Go error to an error code.
+//
+// The error tree is first inspected for values carrying an error code, which
+// are either values with an Errno method, or syscall.Errno values translated
+// from the error numbers of the host to WASI error codes. If none are found,
+// the error is matched against the mappings installed by RegisterErrno. The
+// function returns -1 if the error code could not be determined.
 func AsErrno(err error) Errno {
 	if err == nil {
 		return 0
 	}
-	for {
-		switch e := errors.Unwrap(err).(type) {
-		case nil:
-			return -1 // unknown, just don't return 0
-		case interface{ Errno() int32 }:
-			return Errno(e.Errno())
-		case syscall.Errno:
-			return Errno(int32(e))
-		default:
-			err = e
+	if errno, ok := findErrno(err); ok {
+		return errno
+	}
+	if errno, ok := errnoRegistry.Lookup(err); ok {
+		return errno
+	}
+	return -1 // unknown, just don't return 0
+}
+
+func findErrno(err error) (Errno, bool) {
+	switch e := err.(type) {
+	case interface{ Errno() int32 }:
+		return Errno(e.Errno()), true
+	case syscall.Errno:
+		// Error numbers of the host differ from WASI error codes, unknown
+		// numbers are matched against the registry by AsErrno.
+		errno, ok := syscallErrnos[e]
+		return errno, ok
+	case interface{ Unwrap() error }:
+		if err := e.Unwrap(); err != nil {
+			return findErrno(err)
+		}
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			if errno, ok := findErrno(err); ok {
+				return errno, true
+			}
 		}
 	}
+	return 0, false
 }
@@ -1,13 +1,21 @@
 package types_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
+	"os"
 	"reflect"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 	"unsafe"
 
 	. "github.com/stealthrocket/wazergo/types"
+	"github.com/stealthrocket/wazergo/wasm"
 	"github.com/tetratelabs/wazero/api"
 )
 
@@ -33,11 +41,17 @@ func TestLoadAndStoreValue(t *testing.T) {
 
 	testLoadAndStoreValue(t, Duration(0))
 	testLoadAndStoreValue(t, Duration(1e9))
+
+	testLoadAndStoreValue(t, Enum[whence](2))
+	testLoadAndStoreValue(t, Flags[oflags](0x43))
+
+	testLoadAndStoreValue(t, MakeV128(1, 2))
+	testLoadAndStoreValue(t, V128{0: 0xFF, 15: 0xEE})
 }
 
 func testLoadAndStoreValue[T ParamResult[T]](t *testing.T, value T) {
 	var loaded T
-	var stack = make([]uint64, len(value.ValueTypes()))
+	var stack = make([]uint64, StackSize(value.ValueTypes()))
 
 	value.StoreValue(nil, stack)
 	loaded = loaded.LoadValue(nil, stack)
@@ -53,7 +67,7 @@ func testLoadAndStoreValue[T ParamResult[T]](t *testing.T, value T) {
 	var optionalValue Optional[T]
 	var optionalLoaded Optional[T]
 
-	stack = make([]uint64, len(optionalValue.ValueTypes()))
+	stack = make([]uint64, StackSize(optionalValue.ValueTypes()))
 	optionalValue = Res(value)
 	optionalValue.StoreValue(nil, stack)
 	optionalLoaded = optionalLoaded.LoadValue(nil, stack)
@@ -108,6 +122,770 @@ func TestLoadAndStoreObject(t *testing.T) {
 	testLoadAndStoreObject(t, Duration(1e9))
 
 	testLoadAndStoreObject(t, Vec3d{1, 2, 3})
+
+	testLoadAndStoreObject(t, Enum[whence](2))
+	testLoadAndStoreObject(t, Flags[oflags](0x43))
+
+	testLoadAndStoreObject(t, FixedArray[Uint8, six]{1, 2, 3, 4, 5, 6})
+	testLoadAndStoreObject(t, FixedArray[Vec3d, two]{{1, 2, 3}, {4, 5, 6}})
+	testLoadAndStoreObject(t, FixedArray[FixedArray[Int32, two], two]{{1, 2}, {3, 4}})
+
+	testLoadAndStoreObject(t, Int128{Lo: 1, Hi: -2})
+	testLoadAndStoreObject(t, Uint128{Lo: 1, Hi: 2})
+	testLoadAndStoreObject(t, MakeV128(1, 2))
+}
+
+func TestInt128(t *testing.T) {
+	testFormatObject(t, Int128{}, `0`)
+	testFormatObject(t, Int128{Lo: 42}, `42`)
+	testFormatObject(t, Int128{Lo: ^uint64(0), Hi: -1}, `-1`)
+	testFormatObject(t, Int128{Lo: 0, Hi: -1 << 63}, `-170141183460469231731687303715884105728`)
+	testFormatObject(t, Uint128{Lo: 0, Hi: 1}, `18446744073709551616`)
+	testFormatObject(t, Uint128{Lo: ^uint64(0), Hi: ^uint64(0)}, `340282366920938463463374607431768211455`)
+
+	object := make([]byte, 16)
+	Uint128{Lo: 0x0706050403020100, Hi: 0x0F0E0D0C0B0A0908}.StoreObject(nil, object)
+	assertEqual(t, object, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15})
+}
+
+func TestV128(t *testing.T) {
+	v := MakeV128(0x0706050403020100, 0x0F0E0D0C0B0A0908)
+	assertEqual(t, v, V128{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15})
+	assertEqual(t, StackSize(v.ValueTypes()), 2)
+	assertEqual(t, StackSize(Optional[V128]{}.ValueTypes()), 3)
+
+	testFormatValue(t, v, nil, []uint64{1, 2}, `0x00000000000000020000000000000001`)
+	testFormatValue(t, Optional[V128]{}, nil, []uint64{1, 2, 0}, `0x00000000000000020000000000000001`)
+	testFormatValue(t, Optional[V128]{}, nil, []uint64{0, 0, 8}, `ERROR: errno(8)`)
+}
+
+type two struct{}
+
+func (two) Len() int { return 2 }
+
+type six struct{}
+
+func (six) Len() int { return 6 }
+
+func TestFixedArray(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+
+	ptr := Ptr[FixedArray[Uint16, six]](memory, 8)
+	ptr.Store(FixedArray[Uint16, six]{1, 2, 3})
+	assertEqual(t, ptr.Load(), FixedArray[Uint16, six]{1, 2, 3, 0, 0, 0})
+
+	// The array is laid out inline in memory, the first item is at the
+	// address of the pointer.
+	assertEqual(t, Ptr[Uint16](memory, 8).Load(), Uint16(1))
+	assertEqual(t, Ptr[Uint16](memory, 12).Load(), Uint16(3))
+
+	list := MakeList(Ptr[FixedArray[Uint8, two]](memory, 32), 2)
+	list.Index(0).Store(FixedArray[Uint8, two]{1, 2})
+	list.Index(1).Store(FixedArray[Uint8, two]{3, 4})
+	assertEqual(t, list.Slice(), []FixedArray[Uint8, two]{{1, 2}, {3, 4}})
+
+	b, _ := memory.Read(32, 4)
+	assertEqual(t, b, []byte{1, 2, 3, 4})
+}
+
+func TestStoreSlice(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+
+	Ptr[Uint32](memory, 0).StoreSlice([]Uint32{1, 2, 3})
+	assertEqual(t, Ptr[Uint32](memory, 0).Slice(3), []Uint32{1, 2, 3})
+
+	Ptr[Uint32](memory, 4).UnsafeStoreSlice([]Uint32{4, 5})
+	assertEqual(t, Ptr[Uint32](memory, 0).UnsafeSlice(3), []Uint32{1, 4, 5})
+
+	Ptr[Vec3d](memory, 16).StoreSlice([]Vec3d{{1, 2, 3}, {4, 5, 6}})
+	assertEqual(t, Ptr[Vec3d](memory, 16).Slice(2), []Vec3d{{1, 2, 3}, {4, 5, 6}})
+
+	list := MakeList(Ptr[Int16](memory, 64), 3)
+	list.StoreSlice([]Int16{-1, -2})
+	assertEqual(t, list.Slice(), []Int16{-1, -2, 0})
+	list.UnsafeStoreSlice([]Int16{-3, -4, -5})
+	assertEqual(t, list.Slice(), []Int16{-3, -4, -5})
+	assertEqual(t, list.CopyFrom([]Int16{1, 2, 3, 4}), 3)
+	assertEqual(t, list.Slice(), []Int16{1, 2, 3})
+
+	assertPanic(t, func() { list.StoreSlice([]Int16{1, 2, 3, 4}) })
+	assertPanic(t, func() { Ptr[Uint32](memory, wasm.PageSize-4).StoreSlice([]Uint32{1, 2}) })
+	assertEqual(t, Ptr[Uint32](memory, wasm.PageSize-4).Load(), Uint32(0))
+}
+
+func TestListWriter(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	list := MakeList(Ptr[Uint64](memory, 0), 3)
+	w := list.Writer()
+
+	if n, err := w.Write(1, 2); n != 2 || err != nil {
+		t.Errorf("wrong write result: n=%d err=%v", n, err)
+	}
+	if n, err := w.Write(3, 4); n != 1 || err != io.ErrShortWrite {
+		t.Errorf("wrong write result: n=%d err=%v", n, err)
+	}
+	assertEqual(t, w.Len(), 3)
+	assertEqual(t, w.Available(), 0)
+	assertEqual(t, list.Slice(), []Uint64{1, 2, 3})
+}
+
+func TestNullable(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+
+	null := NullPtr[Uint32](memory, 0)
+	assertEqual(t, null.IsNil(), true)
+	assertPanic(t, func() { null.Load() })
+	assertPanic(t, func() { null.Store(42) })
+
+	ptr := NullPtr[Uint32](memory, 4)
+	ptr.Store(42)
+	assertEqual(t, ptr.IsNil(), false)
+	assertEqual(t, ptr.Load(), Uint32(42))
+
+	var arg Nullable[Uint32]
+	assertEqual(t, arg.LoadValue(memory, []uint64{0}).IsNil(), true)
+	assertEqual(t, arg.LoadValue(memory, []uint64{4}).Load(), Uint32(42))
+
+	testFormatValue(t, arg, memory, []uint64{0}, `NULL`)
+	testFormatValue(t, arg, memory, []uint64{4}, `&42`)
+}
+
+func TestMaybe(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.Write(8, []byte("hello"))
+
+	var arg Maybe[String]
+	// The length is ignored when the address is NULL, the memory area at
+	// address zero must not be read.
+	v, ok := arg.LoadValue(memory, []uint64{0, 5}).Get()
+	assertEqual(t, v, String(""))
+	assertEqual(t, ok, false)
+
+	v, ok = arg.LoadValue(memory, []uint64{8, 5}).Get()
+	assertEqual(t, v, String("hello"))
+	assertEqual(t, ok, true)
+
+	// Zero is only tested on the address, a zero length is a valid value.
+	v, ok = arg.LoadValue(memory, []uint64{8, 0}).Get()
+	assertEqual(t, v, String(""))
+	assertEqual(t, ok, true)
+
+	// Addresses beyond the 32 bits range are not NULL, they are out of bounds.
+	assertSegfault(t, wasm.SEGFAULT64{Offset: 1 << 32, Length: 5}, func() {
+		arg.LoadValue(memory, []uint64{1 << 32, 5})
+	})
+
+	testFormatValue(t, arg, memory, []uint64{0, 5}, `NULL`)
+	testFormatValue(t, arg, memory, []uint64{8, 0}, `""`)
+	testFormatValue(t, arg, memory, []uint64{8, 5}, `"hello"`)
+
+	var ptr Maybe[Pointer[Int32]]
+	memory.Write(0, []byte{1, 0, 0, 0})
+	_, ok = ptr.LoadValue(memory, []uint64{0}).Get()
+	assertEqual(t, ok, false)
+	p, ok := ptr.LoadValue(memory, []uint64{4}).Get()
+	assertEqual(t, p.Load(), Int32(0))
+	assertEqual(t, ok, true)
+}
+
+func TestLoadValue64(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.Write(8, []byte("hello"))
+
+	assertEqual(t, Bytes(nil).LoadValue(memory, []uint64{8, 5}), Bytes("hello"))
+	assertEqual(t, String("").LoadValue(memory, []uint64{8, 5}), String("hello"))
+
+	assertSegfault(t, wasm.SEGFAULT64{Offset: 1 << 32, Length: 5}, func() {
+		String("").LoadValue(memory, []uint64{1 << 32, 5})
+	})
+	assertSegfault(t, wasm.SEGFAULT64{Offset: 8, Length: 1 << 32}, func() {
+		Bytes(nil).LoadValue(memory, []uint64{8, 1 << 32})
+	})
+	assertSegfault(t, wasm.SEGFAULT64{Offset: 8, Length: 4 << 32}, func() {
+		List[Uint32]{}.LoadValue(memory, []uint64{8, 1 << 32})
+	})
+	assertSegfault(t, wasm.SEGFAULT64{Offset: 1 << 40, Length: 8}, func() {
+		Pointer[Uint64]{}.LoadValue(memory, []uint64{1 << 40})
+	})
+}
+
+func TestSupports64(t *testing.T) {
+	type vec3 = FixedArray[Uint32, two]
+	type ptrs = FixedArray[Pointer[Uint32], two]
+
+	assertEqual(t, Supports64(Int32(0)), true)
+	assertEqual(t, Supports64(Bytes(nil)), true)
+	assertEqual(t, Supports64(Pointer[Uint32]{}), true)
+	assertEqual(t, Supports64(Pointer[vec3]{}), true)
+	assertEqual(t, Supports64(Optional[Uint32]{}), true)
+
+	assertEqual(t, Supports64(Pointer[Bytes]{}), false)
+	assertEqual(t, Supports64(Pointer[ptrs]{}), false)
+	assertEqual(t, Supports64(Nullable[Pointer[Uint32]]{}), false)
+	assertEqual(t, Supports64(List[Pointer[Uint32]]{}), false)
+	assertEqual(t, Supports64(Maybe[Pointer[Bytes]]{}), false)
+	assertEqual(t, Supports64(Callback1[Int32, Pointer[Uint32]]{}), true)
+	assertEqual(t, Supports64(Callback1[Int32, Pointer[Bytes]]{}), false)
+	assertEqual(t, Supports64(IOVecs(nil)), false)
+	assertEqual(t, Supports64(Strings(nil)), false)
+	assertEqual(t, Supports64(CStrings(nil)), true)
+	assertEqual(t, Supports64(Pointer[UTF8]{}), false)
+}
+
+func assertSegfault(t *testing.T, want error, f func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		assertEqual(t, recover(), want)
+	}()
+	f()
+}
+
+type whence struct{}
+
+func (whence) Symbols() []Symbol {
+	return []Symbol{
+		{0, "SEEK_SET"},
+		{1, "SEEK_CUR"},
+		{2, "SEEK_END"},
+	}
+}
+
+type oflags struct{}
+
+func (oflags) Symbols() []Symbol {
+	return []Symbol{
+		{0x3, "O_RDWR"},
+		{0x1, "O_RDONLY"},
+		{0x2, "O_WRONLY"},
+		{0x40, "O_CREAT"},
+		{0x80, "O_EXCL"},
+	}
+}
+
+func (oflags) Invalid() Errno { return EINVAL }
+
+func TestEnum(t *testing.T) {
+	assertEqual(t, Enum[whence](1).Valid(), true)
+	assertEqual(t, Enum[whence](3).Valid(), false)
+	assertEqual(t, Enum[whence](2).Validate(), nil)
+	assertEqual(t, Enum[whence](3).Validate() != nil, true)
+
+	testFormatValue(t, Enum[whence](0), nil, []uint64{2}, `SEEK_END`)
+	testFormatValue(t, Enum[whence](0), nil, []uint64{42}, `42`)
+}
+
+func TestFlags(t *testing.T) {
+	assertEqual(t, Flags[oflags](0x43).Has(0x40), true)
+	assertEqual(t, Flags[oflags](0x43).Has(0x80), false)
+	assertEqual(t, Flags[oflags](0xC3).Valid(), true)
+	assertEqual(t, Flags[oflags](0x143).Valid(), false)
+	assertEqual(t, Flags[oflags](0x43).Validate(), nil)
+	assertEqual(t, Flags[oflags](0x143).Validate(), error(EINVAL))
+
+	testFormatValue(t, Flags[oflags](0), nil, []uint64{0x43}, `O_RDWR|O_CREAT`)
+	testFormatValue(t, Flags[oflags](0), nil, []uint64{0x41}, `O_RDONLY|O_CREAT`)
+	testFormatValue(t, Flags[oflags](0), nil, []uint64{0x140}, `O_CREAT|0x100`)
+	testFormatValue(t, Flags[oflags](0), nil, []uint64{0}, `0`)
+}
+
+type closer struct{ closed *int }
+
+func (c closer) Close() error { *c.closed++; return nil }
+
+func TestHandleTable(t *testing.T) {
+	var table HandleTable[closer]
+	var closed int
+
+	h0 := table.Insert(closer{&closed})
+	h1 := table.Insert(closer{&closed})
+	h2 := table.Insert(closer{&closed})
+	assertEqual(t, []int32{h0.Handle(), h1.Handle(), h2.Handle()}, []int32{0, 1, 2})
+	assertEqual(t, table.Len(), 3)
+
+	_, ok := table.Remove(1)
+	assertEqual(t, ok, true)
+	_, ok = table.Remove(1)
+	assertEqual(t, ok, false)
+	_, ok = table.Lookup(1)
+	assertEqual(t, ok, false)
+
+	// The lowest free handle is reused first.
+	assertEqual(t, table.Insert(closer{&closed}).Handle(), int32(1))
+	assertEqual(t, table.Insert(closer{&closed}).Handle(), int32(3))
+
+	var handles []int32
+	table.Range(func(h int32, _ closer) bool {
+		handles = append(handles, h)
+		return true
+	})
+	assertEqual(t, handles, []int32{0, 1, 2, 3})
+
+	if err := table.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, closed, 4)
+	assertEqual(t, table.Len(), 0)
+}
+
+var errReadOnly = errors.New("read-only")
+
+func init() {
+	RegisterErrno(errReadOnly, EPERM)
+	RegisterErrno(errShadowed, EEXIST)
+	RegisterErrno(errShadowed, EPIPE)
+}
+
+var errShadowed = errors.New("shadowed")
+
+func TestExternRef(t *testing.T) {
+	assertEqual(t, ExternRef[string]{}.IsNil(), true)
+	assertEqual(t, Ref("hello").IsNil(), false)
+	assertEqual(t, Ref("hello").Handle(), int32(-1))
+
+	testFormatValue(t, ExternRef[string]{}, nil, []uint64{0}, `NULL`)
+	testFormatValue(t, ExternRef[string]{}, nil, []uint64{3}, `externref(2)`)
+	testFormatValue(t, ExternRef[string]{}, nil, []uint64{1 << 40}, `externref(-1)`)
+
+	ref := ExternRef[string]{}.LoadValue(nil, []uint64{3})
+	_, err := ref.Value()
+	assertEqual(t, err, error(EBADF))
+}
+
+func TestExportRef(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.Write(8, []byte("hello"))
+
+	testFormatValue(t, ExportRef{}, memory, []uint64{8, 0}, `NULL`)
+	testFormatValue(t, ExportRef{}, memory, []uint64{8, 5}, `export(hello)`)
+
+	ref := ExportRef{}.LoadValue(memory, []uint64{8, 5})
+	assertEqual(t, ref.Name(), "hello")
+	_, err := ref.Function()
+	assertEqual(t, err, error(ENOENT))
+}
+
+func TestAsErrno(t *testing.T) {
+	tests := []struct {
+		err   error
+		errno Errno
+	}{
+		{nil, 0},
+		{EBADF, EBADF},
+		{os.ErrNotExist, ENOENT},
+		{fs.ErrPermission, EPERM},
+		{context.DeadlineExceeded, ETIMEDOUT},
+		{context.Canceled, ECANCELED},
+		{io.EOF, EIO},
+		{io.ErrShortBuffer, ENOBUFS},
+		{&fs.PathError{Op: "open", Path: "/tmp", Err: fs.ErrExist}, EEXIST},
+		{fmt.Errorf("key foo/bar: %w", errReadOnly), EPERM},
+		{fmt.Errorf("%w: %w", io.EOF, EINVAL), EINVAL},
+		{errShadowed, EPIPE},
+		{errors.New("unknown"), -1},
+	}
+
+	for _, test := range tests {
+		assertEqual(t, AsErrno(test.err), test.errno)
+	}
+
+	_, err := os.Open("/this/path/does/not/exist")
+	assertEqual(t, AsErrno(err), ENOENT)
+}
+
+func TestErrnoRegistry(t *testing.T) {
+	var registry ErrnoRegistry
+	_, ok := registry.Lookup(errReadOnly)
+	assertEqual(t, ok, false)
+
+	registry.Register(errReadOnly, EROFS)
+	registry.Register(fs.ErrNotExist, ENOTDIR)
+	registry.Register(fs.ErrNotExist, EBADF)
+
+	errno, ok := registry.Lookup(fmt.Errorf("put: %w", errReadOnly))
+	assertEqual(t, errno, EROFS)
+	assertEqual(t, ok, true)
+
+	errno, ok = registry.Lookup(&fs.PathError{Err: syscall.ENOENT})
+	assertEqual(t, errno, EBADF)
+	assertEqual(t, ok, true)
+}
+
+func TestBigEndian(t *testing.T) {
+	testLoadAndStoreObject(t, MakeInt16BE(-2))
+	testLoadAndStoreObject(t, MakeInt32BE(-3))
+	testLoadAndStoreObject(t, MakeInt64BE(-4))
+	testLoadAndStoreObject(t, MakeUint16BE(2))
+	testLoadAndStoreObject(t, MakeUint32BE(3))
+	testLoadAndStoreObject(t, MakeUint64BE(4))
+
+	assertEqual(t, MakeUint32BE(0x01020304), Uint32BE{1, 2, 3, 4})
+	assertEqual(t, MakeInt16BE(-2).Int16(), int16(-2))
+	assertEqual(t, MakeUint64BE(1<<56).Uint64(), uint64(1<<56))
+
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.Write(0, []byte{0x45, 0x00, 0x00, 0x54, 0x12, 0x34})
+	assertEqual(t, Ptr[Uint16BE](memory, 2).Load().Uint16(), uint16(0x54))
+	assertEqual(t, Ptr[Uint32BE](memory, 2).Load().Uint32(), uint32(0x00541234))
+
+	type header struct {
+		Version Uint8
+		TOS     Uint8
+		Length  Uint16BE
+		ID      Uint16BE
+	}
+	h := UnsafeLoadObject[structType[header]]([]byte{0x45, 0x00, 0x00, 0x54, 0x12, 0x34})
+	assertEqual(t, h.value.Length.Uint16(), uint16(0x54))
+	assertEqual(t, h.value.ID.Uint16(), uint16(0x1234))
+
+	testFormatObject(t, MakeInt32BE(-42), `-42`)
+	testFormatObject(t, h, `{Version:69,TOS:0,Length:84,ID:4660}`)
+}
+
+func TestParamRegions(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.WriteUint32Le(16, 64) // Bytes header
+	memory.WriteUint32Le(20, 5)
+
+	params := []Value{Bytes(nil), Int32(0), Pointer[Bytes]{}, Pointer[Uint64]{}}
+	stack := []uint64{100, 4, 42, 16, 200}
+
+	assertEqual(t, ParamRegions(memory, params, stack), []wasm.Region{
+		{Offset: 16, Length: 8},
+		{Offset: 64, Length: 5},
+		{Offset: 100, Length: 4},
+		{Offset: 200, Length: 8},
+	})
+
+	stack = []uint64{100, 4, 42, 16, wasm.PageSize}
+	assertEqual(t, ParamRegions(memory, params, stack), []wasm.Region{
+		{Offset: 16, Length: 8},
+		{Offset: 64, Length: 5},
+		{Offset: 100, Length: 4},
+	})
+}
+
+func TestIOVecs(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.Write(100, []byte("hello world"))
+	memory.WriteUint32Le(0, 100) // "hello"
+	memory.WriteUint32Le(4, 5)
+	memory.WriteUint32Le(8, 105) // " "
+	memory.WriteUint32Le(12, 1)
+	memory.WriteUint32Le(16, 106) // "world"
+	memory.WriteUint32Le(20, 5)
+
+	iovs := IOVecs{}.LoadValue(memory, []uint64{0, 3})
+	assertEqual(t, iovs, IOVecs{[]byte("hello"), []byte(" "), []byte("world")})
+	assertEqual(t, iovs.Len(), 11)
+
+	b, err := io.ReadAll(iovs.Reader())
+	assertEqual(t, string(b), "hello world")
+	assertEqual(t, err, nil)
+	assertEqual(t, iovs.Len(), 11)
+
+	w := iovs.Writer()
+	n, err := w.Write([]byte("HELLO-"))
+	assertEqual(t, n, 6)
+	assertEqual(t, err, nil)
+	assertEqual(t, w.Available(), 5)
+	n, err = w.Write([]byte("WORLD!"))
+	assertEqual(t, n, 5)
+	assertEqual(t, err, io.ErrShortWrite)
+	assertEqual(t, w.Len(), 11)
+
+	b, _ = memory.Read(100, 11)
+	assertEqual(t, string(b), "HELLO-WORLD")
+
+	testFormatValue(t, IOVecs{}, memory, []uint64{0, 3}, `IOVecs(3 buffers, 11 bytes)`)
+
+	memory.WriteUint32Le(20, wasm.PageSize)
+	assertSegfault(t, wasm.SEGFAULT{Offset: 106, Length: wasm.PageSize}, func() {
+		IOVecs{}.LoadValue(memory, []uint64{0, 3})
+	})
+	assertSegfault(t, wasm.SEGFAULT64{Offset: 0, Length: 1 << 35}, func() {
+		IOVecs{}.LoadValue(memory, []uint64{0, 1 << 32})
+	})
+}
+
+func TestUTF8(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.Write(16, []byte("héllo\xff"))
+
+	s := UTF8("").LoadValue(memory, []uint64{16, 6})
+	assertEqual(t, s, UTF8("héllo"))
+	assertEqual(t, s.Validate(), nil)
+
+	s = UTF8("").LoadValue(memory, []uint64{16, 7})
+	assertEqual(t, s.Valid(), false)
+	assertEqual(t, s.Validate(), error(EILSEQ))
+
+	assertEqual(t, UTF8("").ValidateValue(memory, []uint64{16, 6}), nil)
+	assertEqual(t, UTF8("").ValidateValue(memory, []uint64{16, 7}), error(EILSEQ))
+
+	testFormatValue(t, UTF8(""), memory, []uint64{16, 6}, `"héllo"`)
+	buffer := new(strings.Builder)
+	UTF8("").FormatObject(buffer, memory, []byte{16, 0, 0, 0, 6, 0, 0, 0})
+	assertEqual(t, buffer.String(), `"héllo"`)
+	assertEqual(t, UTF8("").LoadObject(memory, []byte{16, 0, 0, 0, 6, 0, 0, 0}), UTF8("héllo"))
+}
+
+func TestUTF16(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	// "h€𝄞" followed by an unpaired surrogate
+	memory.Write(16, []byte{'h', 0, 0xAC, 0x20, 0x34, 0xD8, 0x1E, 0xDD, 0x34, 0xD8})
+
+	assertEqual(t, UTF16("").LoadValue(memory, []uint64{16, 4}), UTF16("h€𝄞"))
+	assertEqual(t, UTF16("").LoadValue(memory, []uint64{16, 5}), UTF16("h€𝄞\uFFFD"))
+	assertEqual(t, UTF16("").LoadObject(memory, []byte{16, 0, 0, 0, 2, 0, 0, 0}), UTF16("h€"))
+
+	assertEqual(t, UTF16("").ValidateValue(memory, []uint64{16, 4}), nil)
+	assertEqual(t, UTF16("").ValidateValue(memory, []uint64{16, 5}), error(EILSEQ))
+	assertEqual(t, UTF16("").ValidateValue(memory, []uint64{16, 2}), nil)
+	assertEqual(t, UTF16("").ValidateValue(memory, []uint64{22, 2}), error(EILSEQ))
+
+	testFormatValue(t, UTF16(""), memory, []uint64{16, 2}, `"h€"`)
+	buffer := new(strings.Builder)
+	UTF16("").FormatObject(buffer, memory, []byte{16, 0, 0, 0, 2, 0, 0, 0})
+	assertEqual(t, buffer.String(), `"h€"`)
+
+	assertSegfault(t, wasm.SEGFAULT{Offset: 16, Length: 2 * wasm.PageSize}, func() {
+		UTF16("").LoadValue(memory, []uint64{16, wasm.PageSize})
+	})
+}
+
+func TestStrings(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.WriteString(64, "helloworld")
+	memory.WriteUint32Le(0, 64)
+	memory.WriteUint32Le(4, 5)
+	memory.WriteUint32Le(8, 69)
+	memory.WriteUint32Le(12, 5)
+	memory.WriteUint32Le(16, 64)
+	memory.WriteUint32Le(20, 0)
+
+	assertEqual(t, Strings(nil).LoadValue(memory, []uint64{0, 3}), Strings{"hello", "world", ""})
+	testFormatValue(t, Strings(nil), memory, []uint64{0, 2}, `["hello", "world"]`)
+
+	memory.WriteUint32Le(12, wasm.PageSize)
+	assertSegfault(t, wasm.SEGFAULT{Offset: 69, Length: wasm.PageSize}, func() {
+		Strings(nil).LoadValue(memory, []uint64{0, 2})
+	})
+}
+
+func TestCStrings(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	args := CStrings{"prog", "", "-v"}
+
+	count, size := args.Sizes()
+	assertEqual(t, count, 3)
+	assertEqual(t, size, 9)
+
+	assertEqual(t, args.Store(memory, 0, 64), nil)
+	b, _ := memory.Read(0, 12)
+	assertEqual(t, b, []byte{64, 0, 0, 0, 69, 0, 0, 0, 70, 0, 0, 0})
+	b, _ = memory.Read(64, 9)
+	assertEqual(t, string(b), "prog\x00\x00-v\x00")
+
+	assertEqual(t, CStrings(nil).LoadValue(memory, []uint64{64, 9}), args)
+	assertEqual(t, CStrings(nil).LoadValue(memory, []uint64{64, 8}), args[:2])
+	testFormatValue(t, CStrings(nil), memory, []uint64{64, 9}, `["prog", "", "-v"]`)
+
+	assertSegfault(t, wasm.SEGFAULT{Offset: wasm.PageSize - 4, Length: 9}, func() {
+		args.Store(memory, 0, wasm.PageSize-4)
+	})
+
+	assertEqual(t, CStrings{"a", "b\x00c"}.Store(memory, 0, 128), error(EINVAL))
+	b, _ = memory.Read(0, 4)
+	assertEqual(t, b, []byte{64, 0, 0, 0})
+}
+
+func TestTimestamp(t *testing.T) {
+	now := time.Date(2023, 4, 5, 6, 7, 8, 123456789, time.UTC)
+
+	assertEqual(t, MakeTimestamp[Seconds](now), Timestamp[Seconds](1680674828))
+	assertEqual(t, MakeTimestamp[Milliseconds](now), Timestamp[Milliseconds](1680674828123))
+	assertEqual(t, MakeTimestamp[Microseconds](now), Timestamp[Microseconds](1680674828123456))
+	assertEqual(t, MakeTimestamp[Nanoseconds](now), Timestamp[Nanoseconds](1680674828123456789))
+	assertEqual(t, MakeTimestamp[Milliseconds](now).Time().Equal(now.Truncate(time.Millisecond)), true)
+	assertEqual(t, Timestamp[Milliseconds](-1).Time().Equal(time.Unix(0, -1e6)), true)
+
+	testLoadAndStoreValue(t, MakeTimestamp[Nanoseconds](now))
+	testLoadAndStoreObject(t, MakeTimestamp[Seconds](now))
+
+	testFormatValue(t, Timestamp[Seconds](0), nil, []uint64{1680674828}, `2023-04-05T06:07:08Z`)
+	testFormatValue(t, Timestamp[Milliseconds](0), nil, []uint64{1680674828123}, `2023-04-05T06:07:08.123Z`)
+	testFormatObject(t, MakeTimestamp[Nanoseconds](now), `2023-04-05T06:07:08.123456789Z`)
+}
+
+func TestTimespec(t *testing.T) {
+	now := time.Date(2023, 4, 5, 6, 7, 8, 123456789, time.UTC)
+	ts := MakeTimespec(now)
+
+	assertEqual(t, ts, Timespec{Sec: 1680674828, Nsec: 123456789})
+	assertEqual(t, ts.Time().Equal(now), true)
+
+	testLoadAndStoreObject(t, ts)
+	testFormatObject(t, ts, `2023-04-05T06:07:08.123456789Z`)
+	testFormatObject(t, st(struct {
+		A Timespec
+		B Timespec
+	}{ts, Timespec{}}), `{A:2023-04-05T06:07:08.123456789Z,B:1970-01-01T00:00:00Z}`)
+}
+
+func TestErrorTable(t *testing.T) {
+	table := ErrorTable{1: {"EFOO", "Foo"}, 3: {Name: "EBAR"}}
+
+	for _, test := range []struct {
+		table  ErrorTable
+		errno  Errno
+		format string
+	}{
+		{table, 1, `EFOO (Foo)`},
+		{table, 2, `errno(2)`},
+		{table, 3, `EBAR`},
+		{table, -1, `errno(-1)`},
+		{WASIErrors, ENOENT, `ENOENT (No such file or directory)`},
+		{WASIErrors, ENOTCAPABLE, `ENOTCAPABLE (Capabilities insufficient)`},
+	} {
+		buffer := new(strings.Builder)
+		test.table.Format(buffer, test.errno)
+		assertEqual(t, buffer.String(), test.format)
+	}
+
+	testFormatValue(t, Optional[Int32]{}, nil, []uint64{42, 0}, `42`)
+	testFormatValue(t, Optional[Int32]{}, nil, []uint64{0, 8}, `ERROR: errno(8)`)
+	testFormatError(t, Optional[Int32]{}, []uint64{0, 8}, `ERROR: EBADF (Bad file descriptor)`)
+	testFormatError(t, Errno(0), []uint64{0}, `ESUCCESS (No error occurred)`)
+}
+
+func TestErrorFirst(t *testing.T) {
+	testLoadAndStoreValue(t, ErrorFirst[Int32](Res(Int32(42))))
+	testLoadAndStoreValue(t, ErrorFirst[Int32](Err[Int32](EBADF)))
+
+	stack := []uint64{1, 2, 3}
+	ErrorFirst[Optional[Int32]](Err[Optional[Int32]](EINVAL)).StoreValue(nil, stack)
+	assertEqual(t, stack, []uint64{uint64(EINVAL), 0, 0})
+
+	// Stack values following the result are not modified.
+	stack = []uint64{1, 2, 3}
+	ErrorFirst[Int32](Err[Int32](EINVAL)).StoreValue(nil, stack)
+	assertEqual(t, stack, []uint64{uint64(EINVAL), 0, 3})
+
+	testFormatValue(t, ErrorFirst[Int32]{}, nil, []uint64{0, 42}, `42`)
+	testFormatValue(t, ErrorFirst[Optional[Int32]]{}, nil, []uint64{0, 42, 0, 99}, `42`)
+	testFormatError(t, ErrorFirst[Int32]{}, []uint64{8, 0}, `ERROR: EBADF (Bad file descriptor)`)
+}
+
+func TestNegErrno(t *testing.T) {
+	testLoadAndStoreValue(t, NegErrno[Int32](Res(Int32(42))))
+	testLoadAndStoreValue(t, NegErrno[Int64](Err[Int64](EBADF)))
+
+	stack := make([]uint64, 1)
+	NegErrno[Int32](Err[Int32](ENOENT)).StoreValue(nil, stack)
+	assertEqual(t, stack[0], api.EncodeI32(-44))
+
+	NegErrno[Int64](Err[Int64](errors.New("unknown"))).StoreValue(nil, stack)
+	assertEqual(t, stack[0], api.EncodeI64(-1))
+
+	// Negative values would be interpreted as error codes by the guest.
+	assertPanic(t, func() { NegErrno[Int32](Res(Int32(-1))).StoreValue(nil, stack) })
+
+	testFormatValue(t, NegErrno[Int32]{}, nil, []uint64{42}, `42`)
+	testFormatError(t, NegErrno[Int32]{}, []uint64{api.EncodeI32(-8)}, `ERROR: EBADF (Bad file descriptor)`)
+}
+
+func TestOutput(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	ptr := Ptr[Uint32](memory, 16)
+	stack := make([]uint64, 1)
+
+	Out(ptr, 42, EIO).StoreValue(memory, stack)
+	assertEqual(t, stack[0], uint64(EIO))
+	assertEqual(t, ptr.Load(), Uint32(0))
+
+	Out(ptr, 42, nil).StoreValue(memory, stack)
+	assertEqual(t, stack[0], uint64(0))
+	assertEqual(t, ptr.Load(), Uint32(42))
+
+	assertEqual(t, Output[Uint32]{}.LoadValue(memory, []uint64{29}).Error(), error(EIO))
+	assertEqual(t, Output[Uint32]{}.LoadValue(memory, []uint64{0}).Error(), nil)
+
+	assertEqual(t, Supports64(Output[Uint32]{}), true)
+	assertEqual(t, Supports64(Output[Pointer[Uint32]]{}), false)
+
+	testFormatValue(t, Output[Uint32]{}, nil, []uint64{0}, `(none)`)
+	testFormatError(t, Output[Uint32]{}, []uint64{29}, `ERROR: EIO (I/O error)`)
+}
+
+func TestErrorMessage(t *testing.T) {
+	err := fmt.Errorf("clé invalide: %w", EINVAL) // "é" is 2 bytes long
+
+	for _, test := range []struct {
+		size int
+		text string
+	}{
+		{size: 0, text: ``},
+		{size: 2, text: `cl`},
+		{size: 3, text: `cl`},
+		{size: 4, text: `clé`},
+		{size: 64, text: `clé invalide: errno(28)`},
+	} {
+		buf := make(Bytes, test.size)
+		for i := range buf {
+			buf[i] = 0xFF
+		}
+		stack := make([]uint64, 2)
+		ErrMsg(buf, err).StoreValue(nil, stack)
+
+		assertEqual(t, stack, []uint64{uint64(EINVAL), uint64(len(err.Error()))})
+		assertEqual(t, string(buf[:len(test.text)]), test.text)
+		assertEqual(t, strings.Trim(string(buf[len(test.text):]), "\x00"), "")
+	}
+
+	stack := []uint64{1, 2}
+	ErrMsg(nil, nil).StoreValue(nil, stack)
+	assertEqual(t, stack, []uint64{0, 0})
+
+	testFormatValue(t, ErrorMessage{}, nil, []uint64{0, 0}, `(none)`)
+	testFormatError(t, ErrorMessage{}, []uint64{28, 24}, `ERROR: EINVAL (Invalid argument) (message: 24 bytes)`)
+}
+
+func testFormatError(t *testing.T, value ErrorFormatter, stack []uint64, format string) {
+	t.Helper()
+	buffer := new(strings.Builder)
+	value.FormatError(buffer, nil, stack, WASIErrors)
+
+	if s := buffer.String(); s != format {
+		t.Errorf("error format mismatch: want=%q got=%q", format, s)
+	}
+}
+
+func testFormatValue(t *testing.T, value Value, memory api.Memory, stack []uint64, format string) {
+	t.Helper()
+	buffer := new(strings.Builder)
+	value.FormatValue(buffer, memory, stack)
+
+	if s := buffer.String(); s != format {
+		t.Errorf("value format mismatch: want=%q got=%q", format, s)
+	}
+}
+
+func assertPanic(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	f()
+}
+
+func assertEqual(t *testing.T, got, want any) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("value mismatch: want=%#v got=%#v", want, got)
+	}
 }
 
 func testLoadAndStoreObject[T Object[T]](t *testing.T, value T) {
@@ -146,6 +924,120 @@ func st[T any](v T) structType[T] {
 	return structType[T]{value: v}
 }
 
+type listNode struct {
+	Value Int32             `name:"value"`
+	Next  Pointer[listNode] `name:"next"`
+}
+
+func (n listNode) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	Format(w, n.LoadObject(memory, object))
+}
+
+func (n listNode) LoadObject(memory api.Memory, object []byte) listNode {
+	return listNode{
+		Value: n.Value.LoadObject(memory, object[:4]),
+		Next:  n.Next.LoadObject(memory, object[4:]),
+	}
+}
+
+func (n listNode) StoreObject(memory api.Memory, object []byte) {
+	n.Value.StoreObject(memory, object[:4])
+	n.Next.StoreObject(memory, object[4:])
+}
+
+func (n listNode) ObjectSize() int {
+	return 8
+}
+
+func TestFormatPointer(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	// 16 -> 32 -> 48 -> 16 (cycle), 64 -> out of bounds
+	memory.Write(16, []byte{1, 0, 0, 0, 32, 0, 0, 0})
+	memory.Write(32, []byte{2, 0, 0, 0, 48, 0, 0, 0})
+	memory.Write(48, []byte{3, 0, 0, 0, 16, 0, 0, 0})
+	memory.Write(64, []byte{4, 0, 0, 0, 0, 0, 1, 0})
+
+	var ptr Pointer[listNode]
+	testFormatValue(t, ptr, memory, []uint64{16}, `&{value:1,next:Pointer(0x20)}`)
+	testFormatValue(t, ptr, memory, []uint64{wasm.PageSize}, `Pointer(0x10000) (out of bounds)`)
+
+	for _, test := range []struct {
+		depth  int
+		offset uint64
+		format string
+	}{
+		{depth: 1, offset: 16, format: `&{value:1,next:&{value:2,next:Pointer(0x30)}}`},
+		{depth: 2, offset: 16, format: `&{value:1,next:&{value:2,next:&{value:3,next:Pointer(0x10)}}}`},
+		{depth: 3, offset: 16, format: `&{value:1,next:&{value:2,next:&{value:3,next:Pointer(0x10) (cycle)}}}`},
+		{depth: 3, offset: 64, format: `&{value:4,next:Pointer(0x10000) (out of bounds)}`},
+	} {
+		buffer := new(strings.Builder)
+		config := FormatConfig{MaxDepth: test.depth}
+		ptr.FormatValue(config.Writer(buffer), memory, []uint64{test.offset})
+		assertEqual(t, buffer.String(), test.format)
+	}
+}
+
+func TestFormatPointerOutOfBounds(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.WriteUint32Le(16, 64) // Bytes header
+	memory.WriteUint32Le(20, 5)
+	memory.WriteString(64, "hello")
+	memory.WriteUint32Le(32, 64) // Bytes header with an out of bounds length
+	memory.WriteUint32Le(36, wasm.PageSize)
+
+	var ptr Pointer[Bytes]
+	testFormatValue(t, ptr, memory, []uint64{16}, `&"hello"`)
+	testFormatValue(t, ptr, memory, []uint64{32}, `Pointer(0x20) (out of bounds)`)
+
+	buffer := new(strings.Builder)
+	config := FormatConfig{HexDump: true}
+	ptr.FormatValue(config.Writer(buffer), memory, []uint64{32})
+	assertEqual(t, buffer.String(), `Pointer(0x20) (out of bounds)`)
+}
+
+func TestFormatHexDump(t *testing.T) {
+	data := Bytes("The quick brown fox jumps over the lazy dog")
+	buffer := new(strings.Builder)
+	data.Format(buffer)
+	assertEqual(t, buffer.String(), `"The quick brown fox ... (43 bytes)"`)
+
+	buffer.Reset()
+	data.Format(FormatConfig{MaxBytes: 9}.Writer(buffer))
+	assertEqual(t, buffer.String(), `"The quick... (43 bytes)"`)
+
+	buffer.Reset()
+	data.Format(FormatConfig{HexDump: true, MaxBytes: 20}.Writer(buffer))
+	assertEqual(t, buffer.String(), `(43 bytes)
+00000000  54 68 65 20 71 75 69 63  6b 20 62 72 6f 77 6e 20  |The quick brown |
+00000010  66 6f 78 20                                       |fox |
+... (23 more bytes)
+`)
+}
+
+func TestFormatHexDumpOffset(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.WriteString(0x1234, "hello")
+	memory.WriteUint32Le(16, 0x1234)
+	memory.WriteUint32Le(20, 5)
+
+	config := FormatConfig{HexDump: true}
+	want := "(5 bytes)\n" +
+		"00001234  68 65 6c 6c 6f                                    |hello|\n"
+
+	buffer := new(strings.Builder)
+	Bytes(nil).FormatValue(config.Writer(buffer), memory, []uint64{0x1234, 5})
+	assertEqual(t, buffer.String(), want)
+
+	buffer.Reset()
+	Bytes(nil).FormatObject(config.Writer(buffer), memory, []byte{0x34, 0x12, 0, 0, 5, 0, 0, 0})
+	assertEqual(t, buffer.String(), want)
+
+	buffer.Reset()
+	Ptr[Bytes](memory, 16).Format(config.Writer(buffer))
+	assertEqual(t, buffer.String(), "&"+want)
+}
+
 func TestFormatObject(t *testing.T) {
 	testFormatObject(t, None{}, `(none)`)
 
@@ -179,6 +1071,15 @@ func TestFormatObject(t *testing.T) {
 	testFormatObject(t, st(struct{ F string }{"hello world"}), `{F:"hello world"}`)
 	testFormatObject(t, st(struct{ F []byte }{[]byte("hello world")}), `{F:"hello world"}`)
 	testFormatObject(t, st(struct{ F [3]int32 }{[3]int32{1, 2, 3}}), `{F:[1,2,3]}`)
+	testFormatObject(t, st(struct{ F FixedArray[Uint8, six] }{FixedArray[Uint8, six]{1, 2}}), `{F:[1,2,0,0,0,0]}`)
+
+	testFormatObject(t, st(struct{ F Flags[oflags] }{0xC0}), `{F:O_CREAT|O_EXCL}`)
+
+	testFormatObject(t, Enum[whence](1), `SEEK_CUR`)
+	testFormatObject(t, Flags[oflags](0x82), `O_WRONLY|O_EXCL`)
+
+	testFormatObject(t, FixedArray[Uint8, six]{1, 2, 3, 4, 5, 6}, `[1,2,3,4,5,6]`)
+	testFormatObject(t, FixedArray[Vec3d, two]{{1, 2, 3}, {4, 5, 6}}, `[{x:1,y:2,z:3},{x:4,y:5,z:6}]`)
 }
 
 func testFormatObject[T Object[T]](t *testing.T, value T, format string) {
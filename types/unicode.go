@@ -0,0 +1,225 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/stealthrocket/wazergo/wasm"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// UTF8 is similar to String but for strings which must be valid UTF-8.
+//
+// UTF8 parameters of host functions created by the F* constructors of the
+// wazergo package are validated before the functions are called; invalid
+// strings are rejected with EILSEQ (see Validator). Values loaded by other
+// means, for example from a Pointer[UTF8], are not validated, host functions
+// must call the Validate method to reject invalid strings:
+//
+//	func (m *Module) SetName(ctx context.Context, name Pointer[UTF8]) Error {
+//		if err := name.Load().Validate(); err != nil {
+//			return Fail(err)
+//		}
+//		...
+//	}
+//
+// UTF8 values are represented as a pair of pointer and length on the stack, and
+// in memory. Since the representation in memory holds 32 bits addresses, UTF8
+// objects cannot be loaded from the memory of 64 bits programs.
+//
+// UTF8 values can be loaded from object fields (e.g. with Pointer[UTF8]), but
+// cannot be stored since the content of the string would need to be allocated
+// in the guest memory; like for Bytes, StoreObject panics.
+type UTF8 string
+
+// Valid returns true if arg is a valid UTF-8 string.
+func (arg UTF8) Valid() bool {
+	return utf8.ValidString(string(arg))
+}
+
+// Validate returns nil if arg is a valid UTF-8 string, or EILSEQ otherwise.
+func (arg UTF8) Validate() error {
+	if arg.Valid() {
+		return nil
+	}
+	return EILSEQ
+}
+
+func (arg UTF8) Format(w io.Writer) {
+	fmt.Fprintf(w, "%q", string(arg))
+}
+
+func (arg UTF8) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	arg.LoadValue(memory, stack).Format(w)
+}
+
+func (arg UTF8) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+// ValidateValue returns EILSEQ if the string on the stack is not valid UTF-8.
+func (arg UTF8) ValidateValue(memory api.Memory, stack []uint64) error {
+	if !utf8.Valid(read(memory, stack[0], stack[1])) {
+		return EILSEQ
+	}
+	return nil
+}
+
+func (arg UTF8) LoadValue(memory api.Memory, stack []uint64) UTF8 {
+	return UTF8(read(memory, stack[0], stack[1]))
+}
+
+func (arg UTF8) LoadObject(memory api.Memory, object []byte) UTF8 {
+	offset := binary.LittleEndian.Uint32(object[:4])
+	length := binary.LittleEndian.Uint32(object[4:])
+	return UTF8(wasm.Read(memory, offset, length))
+}
+
+// StoreObject panics, UTF8 values cannot be stored in memory.
+func (arg UTF8) StoreObject(memory api.Memory, object []byte) {
+	panic("NOT IMPLEMENTED")
+}
+
+func (arg UTF8) ObjectSize() int {
+	return 8
+}
+
+func (UTF8) address() {}
+
+func (arg UTF8) Layout32() bool {
+	return true
+}
+
+func (arg UTF8) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
+}
+
+func (arg UTF8) ValueTypes64() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI64, api.ValueTypeI64}
+}
+
+var (
+	_ Param[UTF8] = UTF8("")
+	_ Value64     = UTF8("")
+	_ Validator   = UTF8("")
+	_ Layout32    = UTF8("")
+	_ Formatter   = UTF8("")
+)
+
+// UTF16 is a type representing strings encoded in UTF-16 little-endian, which
+// is the representation used by guests compiled from languages such as
+// AssemblyScript, C#, or JavaScript. The value is decoded to a Go string when
+// loaded, unpaired surrogates are replaced by the Unicode replacement character
+// U+FFFD.
+//
+// Like UTF8, UTF16 parameters of host functions created by the F* constructors
+// of the wazergo package are validated before the functions are called; strings
+// containing unpaired surrogates are rejected with EILSEQ.
+//
+// UTF16 values are represented as a pair of pointer and length on the stack,
+// and in memory. The length is expressed in number of 16 bits code units, not
+// in bytes. As for UTF8, UTF16 objects cannot be loaded from the memory of 64
+// bits programs, and cannot be stored in object fields.
+type UTF16 string
+
+func (arg UTF16) Format(w io.Writer) {
+	fmt.Fprintf(w, "%q", string(arg))
+}
+
+func (arg UTF16) FormatValue(w io.Writer, memory api.Memory, stack []uint64) {
+	arg.LoadValue(memory, stack).Format(w)
+}
+
+func (arg UTF16) FormatObject(w io.Writer, memory api.Memory, object []byte) {
+	arg.LoadObject(memory, object).Format(w)
+}
+
+// ValidateValue returns EILSEQ if the string on the stack contains unpaired
+// surrogates.
+func (arg UTF16) ValidateValue(memory api.Memory, stack []uint64) error {
+	if !validUTF16(arg.units(memory, stack[0], stack[1])) {
+		return EILSEQ
+	}
+	return nil
+}
+
+func (arg UTF16) LoadValue(memory api.Memory, stack []uint64) UTF16 {
+	return arg.load(memory, stack[0], stack[1])
+}
+
+func (arg UTF16) LoadObject(memory api.Memory, object []byte) UTF16 {
+	offset := binary.LittleEndian.Uint32(object[:4])
+	length := binary.LittleEndian.Uint32(object[4:])
+	return arg.load(memory, uint64(offset), uint64(length))
+}
+
+func (arg UTF16) load(memory api.Memory, offset, length uint64) UTF16 {
+	return UTF16(decodeUTF16(arg.units(memory, offset, length)))
+}
+
+func (arg UTF16) units(memory api.Memory, offset, length uint64) []uint16 {
+	if length > math.MaxUint32 {
+		segfault(offset, mul64(length, 2))
+	}
+	data := read(memory, offset, length*2)
+	units := make([]uint16, length)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return units
+}
+
+// StoreObject panics, UTF16 values cannot be stored in memory.
+func (arg UTF16) StoreObject(memory api.Memory, object []byte) {
+	panic("NOT IMPLEMENTED")
+}
+
+func (arg UTF16) ObjectSize() int {
+	return 8
+}
+
+func (UTF16) address() {}
+
+func (arg UTF16) Layout32() bool {
+	return true
+}
+
+func (arg UTF16) ValueTypes() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
+}
+
+func (arg UTF16) ValueTypes64() []api.ValueType {
+	return []api.ValueType{api.ValueTypeI64, api.ValueTypeI64}
+}
+
+var (
+	_ Param[UTF16] = UTF16("")
+	_ Value64      = UTF16("")
+	_ Validator    = UTF16("")
+	_ Layout32     = UTF16("")
+	_ Formatter    = UTF16("")
+)
+
+func decodeUTF16(units []uint16) string {
+	b := make([]byte, 0, len(units))
+	for _, r := range utf16.Decode(units) {
+		b = utf8.AppendRune(b, r)
+	}
+	return string(b)
+}
+
+func validUTF16(units []uint16) bool {
+	for i := 0; i < len(units); i++ {
+		if r := rune(units[i]); utf16.IsSurrogate(r) {
+			if i+1 == len(units) || utf16.DecodeRune(r, rune(units[i+1])) == utf8.RuneError {
+				return false
+			}
+			i++
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package wasm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Allocator is a bump allocator managing a region of a module memory.
+//
+// Memory is allocated by advancing an offset in the region, and only released
+// all at once by calling Reset, which makes the allocator well suited to
+// manage scratch areas whose content is discarded after each call to a
+// function, or to lay out the parameters of functions in tests. The allocator
+// tracks the highest offset it ever reached, which can be used to verify the
+// size of the region needed by a program.
+//
+// Allocator values are safe to use concurrently from multiple goroutines.
+type Allocator struct {
+	mutex  sync.Mutex
+	memory api.Memory
+	region Region
+	offset uint32
+	high   uint32
+}
+
+// NewAllocator constructs an allocator for length bytes of memory starting at
+// offset. The function panics with a SEGFAULT if the region is beyond the
+// range of memory.
+func NewAllocator(memory api.Memory, offset, length uint32) *Allocator {
+	Read(memory, offset, length)
+	return &Allocator{
+		memory: memory,
+		region: Region{offset, length},
+		offset: offset,
+		high:   offset,
+	}
+}
+
+// Memory returns the memory that the allocator manages a region of.
+func (a *Allocator) Memory() api.Memory { return a.memory }
+
+// Region returns the region of memory managed by the allocator.
+func (a *Allocator) Region() Region { return a.region }
+
+// Alloc allocates size bytes of memory aligned on align, which must be zero or
+// a power of two. The method returns the offset of the allocated memory, or
+// false if the region did not have enough space left.
+func (a *Allocator) Alloc(size, align uint32) (offset uint32, ok bool) {
+	offset, _, ok = a.alloc(size, align)
+	return offset, ok
+}
+
+// alloc is the implementation of Alloc, it also returns the current offset of
+// the allocator, read under the same lock as the allocation so it can be used
+// to report allocation failures.
+func (a *Allocator) alloc(size, align uint32) (offset, current uint32, ok bool) {
+	if align&(align-1) != 0 {
+		panic(fmt.Sprintf("wasm.Allocator.Alloc: alignment is not a power of two: %d", align))
+	}
+	if align == 0 {
+		align = 1
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	start := (uint64(a.offset) + uint64(align-1)) &^ uint64(align-1)
+	end := start + uint64(size)
+	if end > a.region.End() {
+		return 0, a.offset, false
+	}
+	a.offset = uint32(end)
+	if a.offset > a.high {
+		a.high = a.offset
+	}
+	return uint32(start), a.offset, true
+}
+
+// Bytes is like Alloc but returns the allocated memory as a byte slice. The
+// method panics with a SEGFAULT if the region did not have enough space left.
+func (a *Allocator) Bytes(size, align uint32) ([]byte, uint32) {
+	offset, current, ok := a.alloc(size, align)
+	if !ok {
+		panic(SEGFAULT{current, size})
+	}
+	return Read(a.memory, offset, size), offset
+}
+
+// Reset releases all the memory allocated by a. The high-water mark is not
+// reset.
+func (a *Allocator) Reset() {
+	a.mutex.Lock()
+	a.offset = a.region.Offset
+	a.mutex.Unlock()
+}
+
+// Used returns the number of bytes currently allocated, including padding
+// inserted to align the allocations.
+func (a *Allocator) Used() uint32 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.offset - a.region.Offset
+}
+
+// Available returns the number of bytes that remain available for allocation.
+func (a *Allocator) Available() uint32 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return uint32(a.region.End() - uint64(a.offset))
+}
+
+// HighWaterMark returns the maximum number of bytes that were allocated at
+// once since the allocator was created.
+func (a *Allocator) HighWaterMark() uint32 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.high - a.region.Offset
+}
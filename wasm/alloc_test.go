@@ -0,0 +1,80 @@
+package wasm_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stealthrocket/wazergo/wasm"
+)
+
+func TestAllocator(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	alloc := wasm.NewAllocator(memory, 100, 32)
+
+	for _, test := range []struct {
+		size, align uint32
+		offset      uint32
+		ok          bool
+	}{
+		{size: 3, align: 0, offset: 100, ok: true},
+		{size: 8, align: 8, offset: 104, ok: true},
+		{size: 1, align: 1, offset: 112, ok: true},
+		{size: 4, align: 4, offset: 116, ok: true},
+		{size: 16, align: 1, ok: false},
+		{size: 12, align: 4, offset: 120, ok: true},
+		{size: 0, align: 1, offset: 132, ok: true},
+		{size: 1, align: 1, ok: false},
+	} {
+		offset, ok := alloc.Alloc(test.size, test.align)
+		if offset != test.offset || ok != test.ok {
+			t.Fatalf("Alloc(%d, %d): want=(%d, %t) got=(%d, %t)", test.size, test.align, test.offset, test.ok, offset, ok)
+		}
+	}
+
+	if used, avail := alloc.Used(), alloc.Available(); used != 32 || avail != 0 {
+		t.Fatalf("wrong usage: used=%d available=%d", used, avail)
+	}
+
+	alloc.Reset()
+	b, offset := alloc.Bytes(4, 4)
+	if len(b) != 4 || offset != 100 {
+		t.Fatalf("wrong allocation after reset: %d/%d", offset, len(b))
+	}
+	if used, high := alloc.Used(), alloc.HighWaterMark(); used != 4 || high != 32 {
+		t.Fatalf("wrong usage: used=%d high-water=%d", used, high)
+	}
+
+	testSegfault(t, wasm.SEGFAULT{Offset: 104, Length: 29}, func() {
+		alloc.Bytes(29, 1)
+	})
+	testSegfault(t, wasm.SEGFAULT{Offset: wasm.PageSize - 16, Length: 32}, func() {
+		wasm.NewAllocator(memory, wasm.PageSize-16, 32)
+	})
+}
+
+func TestAllocatorConcurrency(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	alloc := wasm.NewAllocator(memory, 0, 1024)
+	offsets := make([]uint32, 128)
+
+	var wg sync.WaitGroup
+	for i := range offsets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			offsets[i], _ = alloc.Alloc(8, 8)
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[uint32]bool)
+	for _, offset := range offsets {
+		if seen[offset] {
+			t.Fatalf("offset allocated twice: %d", offset)
+		}
+		seen[offset] = true
+	}
+	if alloc.Available() != 0 {
+		t.Fatalf("memory not fully allocated: %d", alloc.Available())
+	}
+}
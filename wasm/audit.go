@@ -0,0 +1,296 @@
+package wasm
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// AccessKind represents the kind of memory accesses recorded by AuditMemory.
+type AccessKind uint8
+
+const (
+	ReadAccess AccessKind = iota
+	WriteAccess
+)
+
+func (kind AccessKind) String() string {
+	switch kind {
+	case ReadAccess:
+		return "read"
+	case WriteAccess:
+		return "write"
+	default:
+		return fmt.Sprintf("AccessKind(%d)", uint8(kind))
+	}
+}
+
+// Region represents a range of memory.
+type Region struct{ Offset, Length uint32 }
+
+// End returns the offset of the first byte after the region.
+func (r Region) End() uint64 { return uint64(r.Offset) + uint64(r.Length) }
+
+// Contains returns true if the range of length bytes starting at offset is
+// fully contained in r.
+func (r Region) Contains(offset, length uint32) bool {
+	return offset >= r.Offset && uint64(offset)+uint64(length) <= r.End()
+}
+
+func (r Region) String() string {
+	return fmt.Sprintf("@%08x/%d", r.Offset, r.Length)
+}
+
+// Access is a record of a memory access made through an AuditMemory.
+type Access struct {
+	Region
+	Kind AccessKind
+	// Denied is true if the access was outside of the declared regions and
+	// rejected by the memory.
+	Denied bool
+	// OK is the result of the access, false if it was denied or out of the
+	// bounds of the memory.
+	OK bool
+}
+
+func (a Access) String() string {
+	s := a.Kind.String() + " " + a.Region.String()
+	switch {
+	case a.Denied:
+		s += " (denied)"
+	case !a.OK:
+		s += " (out of bounds)"
+	}
+	return s
+}
+
+// AuditSummary is a summary of the accesses recorded by AuditMemory. The
+// regions are sorted by offset and adjacent or overlapping accesses merged.
+// The byte counts are the sizes of the merged regions, so bytes accessed more
+// than once are only counted once.
+type AuditSummary struct {
+	Reads        []Region
+	Writes       []Region
+	BytesRead    uint64
+	BytesWritten uint64
+	Denied       int
+}
+
+// AuditOption represents options applied to memories constructed by
+// NewAuditMemory.
+type AuditOption func(*AuditMemory)
+
+// WithRegions declares the regions of memory that the audited code is allowed
+// to access, typically the memory areas of the parameters of a host function.
+// Accesses outside of the regions are recorded and rejected, which results in
+// a SEGFAULT when the memory is used with Read.
+//
+// The regions of the parameters of host functions can be obtained with the
+// ParamRegions function of the types package.
+func WithRegions(regions ...Region) AuditOption {
+	return func(mem *AuditMemory) {
+		mem.regions = append(mem.regions, regions...)
+		mem.restrict = true
+	}
+}
+
+// AuditMemory is an implementation of api.Memory wrapping another memory and
+// recording all the accesses made through it. It is safe to use concurrently
+// from multiple goroutines.
+//
+// Accesses are recorded when the methods of api.Memory are called. Since Read
+// returns a view of the memory, writes made through the returned byte slices
+// cannot be recorded as they happen; the memory takes a snapshot of the views
+// instead, and Summary reports the bytes which differ from the snapshots as
+// written. Writing the same values that the memory already held is therefore
+// not reported, and changes made by other means after a view was obtained are
+// reported as well.
+type AuditMemory struct {
+	api.Memory
+	mutex    sync.Mutex
+	accesses []Access
+	views    []*Snapshot
+	regions  []Region
+	restrict bool
+}
+
+// NewAuditMemory constructs a memory recording the accesses to memory.
+func NewAuditMemory(memory api.Memory, options ...AuditOption) *AuditMemory {
+	mem := &AuditMemory{Memory: memory}
+	for _, opt := range options {
+		opt(mem)
+	}
+	return mem
+}
+
+// Accesses returns the list of accesses recorded by the memory, in the order
+// they were made.
+func (mem *AuditMemory) Accesses() []Access {
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+	return append([]Access(nil), mem.accesses...)
+}
+
+// Summary returns a summary of the accesses recorded by the memory, including
+// the writes made through the byte slices returned by Read.
+func (mem *AuditMemory) Summary() (summary AuditSummary) {
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+	for _, a := range mem.accesses {
+		switch {
+		case a.Denied:
+			summary.Denied++
+		case !a.OK:
+		case a.Kind == ReadAccess:
+			summary.Reads = append(summary.Reads, a.Region)
+		case a.Kind == WriteAccess:
+			summary.Writes = append(summary.Writes, a.Region)
+		}
+	}
+	for _, view := range mem.views {
+		for _, change := range view.Diff(mem.Memory) {
+			summary.Writes = append(summary.Writes, change.Region())
+		}
+	}
+	summary.Reads = mergeRegions(summary.Reads)
+	summary.Writes = mergeRegions(summary.Writes)
+	summary.BytesRead = regionsLength(summary.Reads)
+	summary.BytesWritten = regionsLength(summary.Writes)
+	return summary
+}
+
+func regionsLength(regions []Region) (n uint64) {
+	for _, r := range regions {
+		n += uint64(r.Length)
+	}
+	return n
+}
+
+// Reset clears the list of recorded accesses, and the snapshots of the byte
+// slices returned by Read.
+func (mem *AuditMemory) Reset() {
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+	mem.accesses = mem.accesses[:0]
+	mem.views = mem.views[:0]
+}
+
+func (mem *AuditMemory) audit(kind AccessKind, offset, length uint32, access func() bool) bool {
+	mem.mutex.Lock()
+	defer mem.mutex.Unlock()
+	a := Access{Region: Region{offset, length}, Kind: kind}
+	if mem.restrict && !mem.allowed(offset, length) {
+		a.Denied = true
+	} else {
+		a.OK = access()
+	}
+	mem.accesses = append(mem.accesses, a)
+	return a.OK
+}
+
+func (mem *AuditMemory) allowed(offset, length uint32) bool {
+	for _, r := range mem.regions {
+		if r.Contains(offset, length) {
+			return true
+		}
+	}
+	return false
+}
+
+func (mem *AuditMemory) ReadByte(offset uint32) (v byte, ok bool) {
+	mem.audit(ReadAccess, offset, 1, func() bool { v, ok = mem.Memory.ReadByte(offset); return ok })
+	return v, ok
+}
+
+func (mem *AuditMemory) ReadUint16Le(offset uint32) (v uint16, ok bool) {
+	mem.audit(ReadAccess, offset, 2, func() bool { v, ok = mem.Memory.ReadUint16Le(offset); return ok })
+	return v, ok
+}
+
+func (mem *AuditMemory) ReadUint32Le(offset uint32) (v uint32, ok bool) {
+	mem.audit(ReadAccess, offset, 4, func() bool { v, ok = mem.Memory.ReadUint32Le(offset); return ok })
+	return v, ok
+}
+
+func (mem *AuditMemory) ReadUint64Le(offset uint32) (v uint64, ok bool) {
+	mem.audit(ReadAccess, offset, 8, func() bool { v, ok = mem.Memory.ReadUint64Le(offset); return ok })
+	return v, ok
+}
+
+func (mem *AuditMemory) ReadFloat32Le(offset uint32) (v float32, ok bool) {
+	mem.audit(ReadAccess, offset, 4, func() bool { v, ok = mem.Memory.ReadFloat32Le(offset); return ok })
+	return v, ok
+}
+
+func (mem *AuditMemory) ReadFloat64Le(offset uint32) (v float64, ok bool) {
+	mem.audit(ReadAccess, offset, 8, func() bool { v, ok = mem.Memory.ReadFloat64Le(offset); return ok })
+	return v, ok
+}
+
+func (mem *AuditMemory) Read(offset, length uint32) (b []byte, ok bool) {
+	mem.audit(ReadAccess, offset, length, func() bool {
+		if b, ok = mem.Memory.Read(offset, length); ok {
+			mem.views = append(mem.views, &Snapshot{offset: offset, data: bytes.Clone(b)})
+		}
+		return ok
+	})
+	return b, ok
+}
+
+func (mem *AuditMemory) WriteByte(offset uint32, v byte) bool {
+	return mem.audit(WriteAccess, offset, 1, func() bool { return mem.Memory.WriteByte(offset, v) })
+}
+
+func (mem *AuditMemory) WriteUint16Le(offset uint32, v uint16) bool {
+	return mem.audit(WriteAccess, offset, 2, func() bool { return mem.Memory.WriteUint16Le(offset, v) })
+}
+
+func (mem *AuditMemory) WriteUint32Le(offset uint32, v uint32) bool {
+	return mem.audit(WriteAccess, offset, 4, func() bool { return mem.Memory.WriteUint32Le(offset, v) })
+}
+
+func (mem *AuditMemory) WriteUint64Le(offset uint32, v uint64) bool {
+	return mem.audit(WriteAccess, offset, 8, func() bool { return mem.Memory.WriteUint64Le(offset, v) })
+}
+
+func (mem *AuditMemory) WriteFloat32Le(offset uint32, v float32) bool {
+	return mem.audit(WriteAccess, offset, 4, func() bool { return mem.Memory.WriteFloat32Le(offset, v) })
+}
+
+func (mem *AuditMemory) WriteFloat64Le(offset uint32, v float64) bool {
+	return mem.audit(WriteAccess, offset, 8, func() bool { return mem.Memory.WriteFloat64Le(offset, v) })
+}
+
+func (mem *AuditMemory) Write(offset uint32, v []byte) bool {
+	return mem.audit(WriteAccess, offset, uint32(len(v)), func() bool { return mem.Memory.Write(offset, v) })
+}
+
+func (mem *AuditMemory) WriteString(offset uint32, v string) bool {
+	return mem.audit(WriteAccess, offset, uint32(len(v)), func() bool { return mem.Memory.WriteString(offset, v) })
+}
+
+func mergeRegions(regions []Region) []Region {
+	if len(regions) == 0 {
+		return nil
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].Offset < regions[j].Offset
+	})
+	merged := regions[:1]
+	for _, r := range regions[1:] {
+		last := &merged[len(merged)-1]
+		if uint64(r.Offset) <= last.End() {
+			if end := r.End(); end > last.End() {
+				last.Length = uint32(end - uint64(last.Offset))
+			}
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	return merged
+}
+
+var _ api.Memory = (*AuditMemory)(nil)
@@ -0,0 +1,107 @@
+package wasm_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stealthrocket/wazergo/wasm"
+)
+
+func TestAuditMemory(t *testing.T) {
+	memory := wasm.NewAuditMemory(wasm.NewFixedSizeMemory(wasm.PageSize))
+
+	memory.WriteUint32Le(0, 42)
+	memory.WriteString(4, "hello")
+	memory.ReadUint64Le(16)
+	memory.Read(20, 4)
+	memory.ReadByte(wasm.PageSize)
+
+	accesses := memory.Accesses()
+	if len(accesses) != 5 {
+		t.Fatalf("wrong number of accesses: %v", accesses)
+	}
+	if s := accesses[4].String(); s != "read @00010000/1 (out of bounds)" {
+		t.Errorf("wrong access: %s", s)
+	}
+
+	want := wasm.AuditSummary{
+		Reads:        []wasm.Region{{16, 8}},
+		Writes:       []wasm.Region{{0, 9}},
+		BytesRead:    8,
+		BytesWritten: 9,
+	}
+	if got := memory.Summary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong summary:\nwant: %+v\ngot:  %+v", want, got)
+	}
+
+	memory.Reset()
+	if accesses := memory.Accesses(); len(accesses) != 0 {
+		t.Errorf("accesses not cleared: %v", accesses)
+	}
+}
+
+func TestAuditMemoryRegions(t *testing.T) {
+	memory := wasm.NewAuditMemory(wasm.NewFixedSizeMemory(wasm.PageSize),
+		wasm.WithRegions(wasm.Region{Offset: 100, Length: 8}),
+	)
+
+	if !memory.WriteUint64Le(100, 1) {
+		t.Error("write in declared region rejected")
+	}
+	if memory.WriteUint32Le(106, 1) {
+		t.Error("write overlapping the end of the region accepted")
+	}
+	testSegfault(t, wasm.SEGFAULT{Offset: 0, Length: 4}, func() {
+		wasm.Read(memory, 0, 4)
+	})
+
+	summary := memory.Summary()
+	if summary.Denied != 2 || summary.BytesWritten != 8 {
+		t.Errorf("wrong summary: %+v", summary)
+	}
+	if s := memory.Accesses()[1].String(); s != "write @0000006a/4 (denied)" {
+		t.Errorf("wrong access: %s", s)
+	}
+}
+
+func TestAuditMemoryViews(t *testing.T) {
+	memory := wasm.NewAuditMemory(wasm.NewFixedSizeMemory(wasm.PageSize))
+
+	b := wasm.Read(memory, 100, 8)
+	copy(b[2:], "hi")
+	b[7] = 1
+
+	want := wasm.AuditSummary{
+		Reads:        []wasm.Region{{100, 8}},
+		Writes:       []wasm.Region{{102, 2}, {107, 1}},
+		BytesRead:    8,
+		BytesWritten: 3,
+	}
+	if got := memory.Summary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong summary:\nwant: %+v\ngot:  %+v", want, got)
+	}
+
+	memory.Reset()
+	if summary := memory.Summary(); summary.BytesWritten != 0 {
+		t.Errorf("views not cleared: %+v", summary)
+	}
+}
+
+func TestAuditMemoryOverlappingViews(t *testing.T) {
+	memory := wasm.NewAuditMemory(wasm.NewFixedSizeMemory(wasm.PageSize))
+
+	b := wasm.Read(memory, 0, 8)
+	wasm.Read(memory, 0, 8)
+	b[0] = 1
+	memory.Write(4, []byte{2})
+
+	want := wasm.AuditSummary{
+		Reads:        []wasm.Region{{0, 8}},
+		Writes:       []wasm.Region{{0, 1}, {4, 1}},
+		BytesRead:    8,
+		BytesWritten: 2,
+	}
+	if got := memory.Summary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong summary:\nwant: %+v\ngot:  %+v", want, got)
+	}
+}
@@ -0,0 +1,204 @@
+package wasm
+
+import (
+	"errors"
+	"io"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// MemoryReader is an implementation of io.Reader, io.ReaderAt, io.Seeker, and
+// io.WriterTo reading from a window of a module memory.
+//
+// The bounds of the window are verified once when the reader is constructed,
+// after which the reader operates directly on the memory of the module without
+// making intermediary copies of the data. Since growing the memory may
+// invalidate the window, readers should not be retained after the host
+// function that created them has returned.
+type MemoryReader struct {
+	data []byte
+	seek int64
+}
+
+// NewMemoryReader constructs a reader for length bytes of memory starting at
+// offset. The function panics with a SEGFAULT if the window is beyond the
+// range of memory.
+func NewMemoryReader(memory api.Memory, offset, length uint32) *MemoryReader {
+	return &MemoryReader{data: Read(memory, offset, length)}
+}
+
+// Len returns the number of bytes that remain to be read.
+func (r *MemoryReader) Len() int {
+	if r.seek >= int64(len(r.data)) {
+		return 0
+	}
+	return len(r.data) - int(r.seek)
+}
+
+// Size returns the size of the memory window.
+func (r *MemoryReader) Size() int64 {
+	return int64(len(r.data))
+}
+
+func (r *MemoryReader) Read(b []byte) (int, error) {
+	n, err := r.ReadAt(b, r.seek)
+	r.seek += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (r *MemoryReader) ReadAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("wasm.MemoryReader.ReadAt: negative offset")
+	}
+	if off >= int64(len(r.data)) {
+		return 0, io.EOF
+	}
+	n := copy(b, r.data[off:])
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *MemoryReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.seek
+	case io.SeekEnd:
+		offset += int64(len(r.data))
+	default:
+		return 0, errors.New("wasm.MemoryReader.Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("wasm.MemoryReader.Seek: negative position")
+	}
+	r.seek = offset
+	return offset, nil
+}
+
+func (r *MemoryReader) WriteTo(w io.Writer) (int64, error) {
+	if r.seek >= int64(len(r.data)) {
+		return 0, nil
+	}
+	b := r.data[r.seek:]
+	n, err := w.Write(b)
+	r.seek += int64(n)
+	if err == nil && n < len(b) {
+		err = io.ErrShortWrite
+	}
+	return int64(n), err
+}
+
+// MemoryWriter is an implementation of io.Writer, io.WriterAt, and
+// io.ReaderFrom writing to a window of a module memory.
+//
+// Like MemoryReader, the bounds of the window are verified once when the
+// writer is constructed, and data is written directly to the memory of the
+// module. Writes beyond the end of the window are truncated and report
+// io.ErrShortWrite.
+type MemoryWriter struct {
+	data []byte
+	size int
+}
+
+// NewMemoryWriter constructs a writer for length bytes of memory starting at
+// offset. The function panics with a SEGFAULT if the window is beyond the
+// range of memory.
+func NewMemoryWriter(memory api.Memory, offset, length uint32) *MemoryWriter {
+	return &MemoryWriter{data: Read(memory, offset, length)}
+}
+
+// Len returns the number of bytes written.
+func (w *MemoryWriter) Len() int {
+	return w.size
+}
+
+// Available returns the number of bytes that can still be written.
+func (w *MemoryWriter) Available() int {
+	return len(w.data) - w.size
+}
+
+func (w *MemoryWriter) Write(b []byte) (int, error) {
+	n := copy(w.data[w.size:], b)
+	w.size += n
+	if n < len(b) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+func (w *MemoryWriter) WriteString(s string) (int, error) {
+	n := copy(w.data[w.size:], s)
+	w.size += n
+	if n < len(s) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+// WriteAt writes b at offset off of the memory window. The method does not
+// change the position of the writer.
+func (w *MemoryWriter) WriteAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("wasm.MemoryWriter.WriteAt: negative offset")
+	}
+	if off >= int64(len(w.data)) {
+		if len(b) == 0 {
+			return 0, nil
+		}
+		return 0, io.ErrShortWrite
+	}
+	n := copy(w.data[off:], b)
+	if n < len(b) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+// ReadFrom reads data from r directly into the memory window, until r returns
+// io.EOF or the window is full. The method returns io.ErrShortWrite as soon as
+// the window is full if r did not report io.EOF yet, without reading more data
+// from r; this also happens when r would have returned io.EOF on the next call
+// to Read. If r returns no data and no error too many times in a row, the
+// method gives up and returns io.ErrNoProgress.
+func (w *MemoryWriter) ReadFrom(r io.Reader) (int64, error) {
+	var n int64
+	for empty := 0; ; {
+		if w.size == len(w.data) {
+			return n, io.ErrShortWrite
+		}
+		c, err := r.Read(w.data[w.size:])
+		w.size += c
+		n += int64(c)
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if c > 0 {
+			empty = 0
+		} else if empty++; empty == maxConsecutiveEmptyReads {
+			return n, io.ErrNoProgress
+		}
+	}
+}
+
+// maxConsecutiveEmptyReads is the number of reads returning no data and no
+// error after which MemoryWriter.ReadFrom gives up, the same limit as the one
+// used by the bufio package.
+const maxConsecutiveEmptyReads = 100
+
+var (
+	_ io.Reader     = (*MemoryReader)(nil)
+	_ io.ReaderAt   = (*MemoryReader)(nil)
+	_ io.Seeker     = (*MemoryReader)(nil)
+	_ io.WriterTo   = (*MemoryReader)(nil)
+	_ io.Writer     = (*MemoryWriter)(nil)
+	_ io.WriterAt   = (*MemoryWriter)(nil)
+	_ io.ReaderFrom = (*MemoryWriter)(nil)
+)
@@ -0,0 +1,115 @@
+package wasm_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stealthrocket/wazergo/wasm"
+)
+
+func TestMemoryReader(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	memory.WriteString(100, "Hello, World!")
+
+	r := wasm.NewMemoryReader(memory, 100, 13)
+	if err := iotest.TestReader(r, []byte("Hello, World!")); err != nil {
+		t.Fatal(err)
+	}
+
+	r = wasm.NewMemoryReader(memory, 107, 5)
+	b := new(bytes.Buffer)
+	if n, err := r.WriteTo(b); n != 5 || err != nil {
+		t.Fatalf("WriteTo: %d, %v", n, err)
+	}
+	if b.String() != "World" || r.Len() != 0 {
+		t.Fatalf("WriteTo: %q (%d remaining)", b, r.Len())
+	}
+
+	// Writes to memory are visible through the reader since it is a view of
+	// the memory and not a copy.
+	memory.WriteString(107, "Wasm!")
+	r.Seek(0, io.SeekStart)
+	if s, _ := io.ReadAll(r); string(s) != "Wasm!" {
+		t.Fatalf("ReadAll: %q", s)
+	}
+
+	testSegfault(t, wasm.SEGFAULT{Offset: wasm.PageSize - 4, Length: 5}, func() {
+		wasm.NewMemoryReader(memory, wasm.PageSize-4, 5)
+	})
+}
+
+func TestMemoryWriter(t *testing.T) {
+	memory := wasm.NewFixedSizeMemory(wasm.PageSize)
+	w := wasm.NewMemoryWriter(memory, 100, 8)
+
+	if n, err := w.Write([]byte("Hello")); n != 5 || err != nil {
+		t.Fatalf("Write: %d, %v", n, err)
+	}
+	if n, err := w.WriteString(", World!"); n != 3 || err != io.ErrShortWrite {
+		t.Fatalf("WriteString: %d, %v", n, err)
+	}
+	if w.Len() != 8 || w.Available() != 0 {
+		t.Fatalf("Len=%d Available=%d", w.Len(), w.Available())
+	}
+	if b, _ := memory.Read(100, 9); string(b) != "Hello, W\x00" {
+		t.Fatalf("memory: %q", b)
+	}
+
+	if n, err := w.WriteAt([]byte("J"), 0); n != 1 || err != nil {
+		t.Fatalf("WriteAt: %d, %v", n, err)
+	}
+	if n, err := w.WriteAt([]byte("xyz"), 6); n != 2 || err != io.ErrShortWrite {
+		t.Fatalf("WriteAt: %d, %v", n, err)
+	}
+	if b, _ := memory.Read(100, 9); string(b) != "Jello,xy\x00" {
+		t.Fatalf("memory: %q", b)
+	}
+
+	w = wasm.NewMemoryWriter(memory, 200, 4)
+	if n, err := io.Copy(w, iotest.OneByteReader(strings.NewReader("abc"))); n != 3 || err != nil {
+		t.Fatalf("ReadFrom: %d, %v", n, err)
+	}
+	w = wasm.NewMemoryWriter(memory, 200, 4)
+	if n, err := w.ReadFrom(iotest.DataErrReader(strings.NewReader("abcd"))); n != 4 || err != nil {
+		t.Fatalf("ReadFrom: %d, %v", n, err)
+	}
+	r := strings.NewReader("abcde")
+	w = wasm.NewMemoryWriter(memory, 200, 4)
+	if n, err := w.ReadFrom(r); n != 4 || err != io.ErrShortWrite {
+		t.Fatalf("ReadFrom: %d, %v", n, err)
+	}
+	if r.Len() != 1 {
+		t.Fatalf("ReadFrom consumed the data beyond the window: %d bytes left", r.Len())
+	}
+	w = wasm.NewMemoryWriter(memory, 200, 4)
+	if _, err := w.ReadFrom(emptyReader{}); err != io.ErrNoProgress {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	w = wasm.NewMemoryWriter(memory, 200, 4)
+	if _, err := w.ReadFrom(iotest.ErrReader(io.ErrUnexpectedEOF)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+
+	testSegfault(t, wasm.SEGFAULT{Offset: wasm.PageSize, Length: 1}, func() {
+		wasm.NewMemoryWriter(memory, wasm.PageSize, 1)
+	})
+}
+
+type emptyReader struct{}
+
+func (emptyReader) Read([]byte) (int, error) { return 0, nil }
+
+func testSegfault(t *testing.T, want wasm.SEGFAULT, f func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		if got, _ := recover().(wasm.SEGFAULT); got != want {
+			t.Fatalf("segfault mismatch: want=%v got=%v", want, got)
+		}
+	}()
+	f()
+}
@@ -10,6 +10,10 @@ import (
 // PageSize is the size of memory pages in WebAssembly programs (64 KiB).
 const PageSize = 64 * 1024
 
+// MaxPages is the maximum number of pages of WebAssembly memories using 32 bits
+// addressing (4 GiB).
+const MaxPages = 65536
+
 func ceil(size uint32) uint32 {
 	size += PageSize - 1
 	size /= PageSize
@@ -19,41 +23,133 @@ func ceil(size uint32) uint32 {
 
 type memoryDefinition struct{ *Memory }
 
-func (def memoryDefinition) ModuleName() string { return "" }
+func (def memoryDefinition) ModuleName() string { return def.moduleName }
 
 func (def memoryDefinition) Index() uint32 { return 0 }
 
-func (def memoryDefinition) Import() (moduleName, name string, isImport bool) { return }
+func (def memoryDefinition) Import() (moduleName, name string, isImport bool) {
+	return def.importModule, def.importName, def.importModule != "" || def.importName != ""
+}
 
-func (def memoryDefinition) ExportNames() []string { return nil }
+func (def memoryDefinition) ExportNames() []string { return def.exportNames }
 
-func (def memoryDefinition) Min() uint32 { return 0 }
+func (def memoryDefinition) Min() uint32 { return def.min }
 
-func (def memoryDefinition) Max() (uint32, bool) { return ceil(uint32(len(def.memory))), true }
+func (def memoryDefinition) Max() (uint32, bool) {
+	if !def.hasMax {
+		return MaxPages, false
+	}
+	return def.max, true
+}
 
 // Memory is an implementation of the api.Memory interface of wazero backed by
-// a Go byte slice. The memory has a fixed size and cannot grow nor shrink.
+// a Go byte slice.
 //
 // This type is mostly useful in tests to construct memory areas where output
-// parameters can be stored.
+// parameters can be stored, or to stand in for the memory of a guest module
+// when testing host functions which grow the memory.
 type Memory struct {
 	memory []byte
 	api.Memory
+
+	min, max     uint32
+	hasMax       bool
+	onGrow       func(previousPages, deltaPages uint32) bool
+	moduleName   string
+	exportNames  []string
+	importModule string
+	importName   string
+}
+
+// MemoryOption represents options applied to memories constructed by
+// NewMemory.
+type MemoryOption func(*Memory)
+
+// WithMinPages sets the initial number of pages of the memory.
+func WithMinPages(pages uint32) MemoryOption {
+	return func(mem *Memory) { mem.min = pages }
+}
+
+// WithMaxPages sets the maximum number of pages that the memory can grow to.
+// By default, memories can grow up to MaxPages and the definition reports that
+// the maximum is unbounded.
+func WithMaxPages(pages uint32) MemoryOption {
+	return func(mem *Memory) { mem.max, mem.hasMax = pages, true }
+}
+
+// WithGrowHook installs a function called each time the memory is about to
+// grow, with the current size and number of pages being added. The memory
+// only grows if the function returns true, which can be used to simulate the
+// failure of memory allocations.
+func WithGrowHook(hook func(previousPages, deltaPages uint32) bool) MemoryOption {
+	return func(mem *Memory) { mem.onGrow = hook }
+}
+
+// WithExport sets the name of the module that the memory is defined in, and
+// the names that it is exported as.
+func WithExport(moduleName string, exportNames ...string) MemoryOption {
+	return func(mem *Memory) { mem.moduleName, mem.exportNames = moduleName, exportNames }
+}
+
+// WithImport declares that the memory was imported from the given module and
+// name.
+func WithImport(moduleName, name string) MemoryOption {
+	return func(mem *Memory) { mem.importModule, mem.importName = moduleName, name }
+}
+
+// NewMemory constructs a Memory instance configured by the list of options.
+// Without options, the memory is initially empty and can grow up to MaxPages.
+//
+// The function panics if the minimum number of pages is greater than the
+// maximum.
+func NewMemory(options ...MemoryOption) *Memory {
+	mem := new(Memory)
+	for _, opt := range options {
+		opt(mem)
+	}
+	if mem.min > MaxPages || (mem.hasMax && mem.min > mem.max) {
+		panic("wasm.NewMemory: minimum number of pages is greater than the maximum")
+	}
+	if mem.max > MaxPages {
+		mem.max = MaxPages
+	}
+	mem.memory = make([]byte, uint64(mem.min)*PageSize)
+	return mem
 }
 
 // NewFixedSizeMemory constructs a Memory instance of size bytes aligned on the
-// WebAssembly page size.
+// WebAssembly page size. The memory cannot grow nor shrink.
 func NewFixedSizeMemory(size uint32) *Memory {
-	return &Memory{
-		memory: make([]byte, ceil(size)),
-	}
+	pages := ceil(size) / PageSize
+	return NewMemory(WithMinPages(pages), WithMaxPages(pages))
 }
 
 func (mem *Memory) Definition() api.MemoryDefinition { return memoryDefinition{Memory: mem} }
 
 func (mem *Memory) Size() uint32 { return uint32(len(mem.memory)) }
 
-func (mem *Memory) Grow(uint32) (uint32, bool) { return ceil(uint32(len(mem.memory))), false }
+// Grow grows the memory by the given number of pages. Like the memory of
+// guest modules, the content is moved to a new location, invalidating the
+// byte slices previously returned by Read. Since Memory values cannot be
+// shared, atomic operations made concurrently to growing the memory may be
+// lost.
+func (mem *Memory) Grow(deltaPages uint32) (previousPages uint32, ok bool) {
+	previousPages = uint32(len(mem.memory) / PageSize)
+	if deltaPages == 0 {
+		return previousPages, true
+	}
+	maxPages, _ := mem.Definition().Max()
+	if uint64(previousPages)+uint64(deltaPages) > uint64(maxPages) {
+		return previousPages, false
+	}
+	if mem.onGrow != nil && !mem.onGrow(previousPages, deltaPages) {
+		return previousPages, false
+	}
+	memory := make([]byte, uint64(previousPages+deltaPages)*PageSize)
+	copy(memory, mem.memory)
+	mem.memory = memory
+	return previousPages, true
+}
 
 func (mem *Memory) ReadByte(offset uint32) (byte, bool) {
 	if mem.isOutOfRange(offset, 1) {
@@ -125,7 +221,7 @@ func (mem *Memory) WriteUint32Le(offset uint32, value uint32) bool {
 }
 
 func (mem *Memory) WriteUint64Le(offset uint32, value uint64) bool {
-	if mem.isOutOfRange(offset, 4) {
+	if mem.isOutOfRange(offset, 8) {
 		return false
 	}
 	binary.LittleEndian.PutUint64(mem.memory[offset:], value)
@@ -157,6 +253,5 @@ func (mem *Memory) WriteString(offset uint32, value string) bool {
 }
 
 func (mem *Memory) isOutOfRange(offset, length uint32) bool {
-	size := mem.Size()
-	return offset >= size || length > size || offset > (size-length)
+	return uint64(offset)+uint64(length) > uint64(len(mem.memory))
 }
@@ -0,0 +1,73 @@
+package wasm_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stealthrocket/wazergo/wasm"
+)
+
+func TestMemoryGrow(t *testing.T) {
+	var hooks [][2]uint32
+	memory := wasm.NewMemory(
+		wasm.WithMinPages(1),
+		wasm.WithMaxPages(3),
+		wasm.WithGrowHook(func(previousPages, deltaPages uint32) bool {
+			hooks = append(hooks, [2]uint32{previousPages, deltaPages})
+			return deltaPages < 2 || previousPages > 1
+		}),
+	)
+	memory.WriteString(0, "hello")
+
+	if memory.Size() != wasm.PageSize {
+		t.Fatalf("wrong initial size: %d", memory.Size())
+	}
+	if prev, ok := memory.Grow(2); prev != 1 || ok {
+		t.Fatalf("grow should have been rejected by the hook: %d, %t", prev, ok)
+	}
+	if prev, ok := memory.Grow(1); prev != 1 || !ok {
+		t.Fatalf("grow failed: %d, %t", prev, ok)
+	}
+	if prev, ok := memory.Grow(2); prev != 2 || ok {
+		t.Fatalf("grow beyond the maximum: %d, %t", prev, ok)
+	}
+	if memory.Size() != 2*wasm.PageSize {
+		t.Fatalf("wrong size after grow: %d", memory.Size())
+	}
+	if b, _ := memory.Read(0, 5); string(b) != "hello" {
+		t.Fatalf("content not preserved: %q", b)
+	}
+	if !memory.WriteUint64Le(2*wasm.PageSize-8, 1) || memory.WriteUint64Le(2*wasm.PageSize-4, 1) {
+		t.Fatal("wrong bounds check of 64 bits writes")
+	}
+	if want := [][2]uint32{{1, 2}, {1, 1}}; !reflect.DeepEqual(hooks, want) {
+		t.Fatalf("wrong calls to grow hook: %v", hooks)
+	}
+}
+
+func TestMemoryDefinition(t *testing.T) {
+	def := wasm.NewMemory(wasm.WithMinPages(2), wasm.WithExport("env", "memory")).Definition()
+
+	if def.Min() != 2 {
+		t.Errorf("wrong min: %d", def.Min())
+	}
+	if max, ok := def.Max(); max != wasm.MaxPages || ok {
+		t.Errorf("wrong max: %d, %t", max, ok)
+	}
+	if def.ModuleName() != "env" || !reflect.DeepEqual(def.ExportNames(), []string{"memory"}) {
+		t.Errorf("wrong export: %q %q", def.ModuleName(), def.ExportNames())
+	}
+	if _, _, isImport := def.Import(); isImport {
+		t.Error("memory should not be imported")
+	}
+
+	def = wasm.NewMemory(wasm.WithImport("env", "mem")).Definition()
+	if moduleName, name, isImport := def.Import(); moduleName != "env" || name != "mem" || !isImport {
+		t.Errorf("wrong import: %q %q %t", moduleName, name, isImport)
+	}
+
+	def = wasm.NewFixedSizeMemory(wasm.PageSize + 1).Definition()
+	if max, ok := def.Max(); def.Min() != 2 || max != 2 || !ok {
+		t.Errorf("wrong limits of fixed size memory: %d-%d", def.Min(), max)
+	}
+}
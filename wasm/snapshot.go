@@ -0,0 +1,155 @@
+package wasm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Snapshot is a copy of a region of a module memory, which can be compared to
+// the content of the memory at a later time.
+//
+// Snapshots can be taken of any implementation of api.Memory, including the
+// memories of wazero modules and Memory values.
+type Snapshot struct {
+	offset uint32
+	data   []byte
+}
+
+// TakeSnapshot takes a snapshot of the full content of memory.
+func TakeSnapshot(memory api.Memory) *Snapshot {
+	return TakeSnapshotRegion(memory, 0, memory.Size())
+}
+
+// TakeSnapshotRegion takes a snapshot of length bytes of memory starting at
+// offset. The function panics with a SEGFAULT if the region is beyond the
+// range of memory.
+func TakeSnapshotRegion(memory api.Memory, offset, length uint32) *Snapshot {
+	data := Read(memory, offset, length)
+	return &Snapshot{offset: offset, data: bytes.Clone(data)}
+}
+
+// Region returns the region of memory captured by the snapshot.
+func (s *Snapshot) Region() Region {
+	return Region{s.offset, uint32(len(s.data))}
+}
+
+// Bytes returns the content of the snapshot. The returned byte slice must not
+// be modified.
+func (s *Snapshot) Bytes() []byte {
+	return s.data
+}
+
+// Diff compares the snapshot to the current content of memory, returning the
+// list of changes made in the region captured by the snapshot. If the memory
+// grew since the snapshot was taken, changes beyond the captured region are
+// not reported. The method panics with a SEGFAULT if the region is beyond the
+// range of memory.
+func (s *Snapshot) Diff(memory api.Memory) Diff {
+	data := Read(memory, s.offset, uint32(len(s.data)))
+	var diff Diff
+	for i := 0; i < len(data); {
+		if s.data[i] == data[i] {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(data) && s.data[j] != data[j] {
+			j++
+		}
+		diff = append(diff, Change{
+			Offset: s.offset + uint32(i),
+			Old:    s.data[i:j:j],
+			New:    bytes.Clone(data[i:j]),
+		})
+		i = j
+	}
+	return diff
+}
+
+// Change represents a contiguous sequence of bytes which changed in memory
+// since a snapshot was taken.
+type Change struct {
+	Offset uint32
+	Old    []byte
+	New    []byte
+}
+
+// Region returns the region of memory affected by the change.
+func (c Change) Region() Region {
+	return Region{c.Offset, uint32(len(c.New))}
+}
+
+// Diff is the list of changes returned by Snapshot.Diff, sorted by offset.
+type Diff []Change
+
+// Len returns the total number of bytes that changed.
+func (d Diff) Len() (n int) {
+	for _, c := range d {
+		n += len(c.New)
+	}
+	return n
+}
+
+// Format writes a representation of d to w, where each change is rendered as
+// a pair of hex dumps of the previous and current content of memory.
+func (d Diff) Format(w io.Writer) {
+	for _, c := range d {
+		fmt.Fprintf(w, "%s\n", c.Region())
+		writePrefixedHexDump(w, "- ", c.Offset, c.Old)
+		writePrefixedHexDump(w, "+ ", c.Offset, c.New)
+	}
+}
+
+func (d Diff) String() string {
+	s := new(strings.Builder)
+	d.Format(s)
+	return s.String()
+}
+
+// HexDump writes a hex dump of data to w, in the format of the canonical output
+// of the hexdump command (hexdump -C). Offsets are displayed relative to
+// the offset argument, which is usually the address of data in memory.
+func HexDump(w io.Writer, offset uint32, data []byte) {
+	writePrefixedHexDump(w, "", offset, data)
+}
+
+func writePrefixedHexDump(w io.Writer, prefix string, offset uint32, data []byte) {
+	const hexdigits = "0123456789abcdef"
+	line := make([]byte, 0, 80)
+
+	for len(data) > 0 {
+		n := len(data)
+		if n > 16 {
+			n = 16
+		}
+		row := data[:n]
+		data = data[n:]
+
+		line = append(line[:0], prefix...)
+		line = fmt.Appendf(line, "%08x  ", offset)
+		for i := 0; i < 16; i++ {
+			if i < len(row) {
+				line = append(line, hexdigits[row[i]>>4], hexdigits[row[i]&0xF], ' ')
+			} else {
+				line = append(line, "   "...)
+			}
+			if i == 7 {
+				line = append(line, ' ')
+			}
+		}
+		line = append(line, " |"...)
+		for _, c := range row {
+			if c < 0x20 || c > 0x7E {
+				c = '.'
+			}
+			line = append(line, c)
+		}
+		line = append(line, "|\n"...)
+		w.Write(line)
+		offset += uint32(n)
+	}
+}
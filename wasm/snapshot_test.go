@@ -0,0 +1,79 @@
+package wasm_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stealthrocket/wazergo/wasm"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// memoryModule is a WebAssembly module exporting a memory of one page.
+var memoryModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	0x07, 0x0a, 0x01, 0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+
+	module, err := runtime.Instantiate(ctx, memoryModule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		scenario string
+		memory   api.Memory
+	}{
+		{scenario: "wasm.Memory", memory: wasm.NewFixedSizeMemory(wasm.PageSize)},
+		{scenario: "wazero", memory: module.Memory()},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			memory := test.memory
+			memory.WriteString(0, "Hello, World!")
+
+			snapshot := wasm.TakeSnapshot(memory)
+			if diff := snapshot.Diff(memory); len(diff) != 0 {
+				t.Fatalf("unexpected changes: %v", diff)
+			}
+
+			memory.WriteString(7, "Wasm")
+			memory.WriteUint32Le(100, 0xFFFFFFFF)
+			memory.Grow(1)
+
+			// "World" and "Wasm" share the first byte, which is not reported.
+			const want = `@00000008/3
+- 00000008  6f 72 6c                                          |orl|
++ 00000008  61 73 6d                                          |asm|
+@00000064/4
+- 00000064  00 00 00 00                                       |....|
++ 00000064  ff ff ff ff                                       |....|
+`
+			diff := snapshot.Diff(memory)
+			if diff.Len() != 7 {
+				t.Errorf("wrong number of bytes changed: %d", diff.Len())
+			}
+			if got := diff.String(); got != want {
+				t.Errorf("wrong diff:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestHexDump(t *testing.T) {
+	b := new(strings.Builder)
+	wasm.HexDump(b, 0x10, []byte("The quick brown fox\x00\x01"))
+
+	const want = `00000010  54 68 65 20 71 75 69 63  6b 20 62 72 6f 77 6e 20  |The quick brown |
+00000020  66 6f 78 00 01                                    |fox..|
+`
+	if got := b.String(); got != want {
+		t.Errorf("wrong hex dump:\n%s", got)
+	}
+}
@@ -15,6 +15,15 @@ func (err SEGFAULT) Error() string {
 	return fmt.Sprintf("segmentation fault: @%08x/%d", err.Offset, err.Length)
 }
 
+// SEGFAULT64 is like SEGFAULT but for invalid memory accesses of programs using
+// 64 bits addressing (memory64), where the offset or length do not fit in 32
+// bits and are therefore always beyond the range of wazero memories.
+type SEGFAULT64 struct{ Offset, Length uint64 }
+
+func (err SEGFAULT64) Error() string {
+	return fmt.Sprintf("segmentation fault: @%08x/%d", err.Offset, err.Length)
+}
+
 // Read returns a byte slice from a module memory. The function calls Read on
 // the given memory and panics if offset/length are beyond the range of memory.
 func Read(memory api.Memory, offset, length uint32) []byte {